@@ -0,0 +1,169 @@
+package falcon
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// AutoTLSConfig defines how StartAutoTLSWithConfig and
+// RunAutoTLSWithConfig obtain certificates over ACME (Let's Encrypt by
+// default). Certificates are requested on the first TLS handshake for a
+// host and renewed automatically.
+type AutoTLSConfig struct {
+	// Hosts lists the host names certificates may be requested for.
+	// Required.
+	Hosts []string
+
+	// CacheDir is the directory certificates and the account key are
+	// stored in. Defaults to "certs". Ignored when Cache is set.
+	CacheDir string
+
+	// Cache stores certificates somewhere else than a local directory,
+	// e.g. in a database shared by several replicas.
+	Cache autocert.Cache
+
+	// DirectoryURL is the ACME directory, e.g. the Let's Encrypt staging
+	// environment or a local test CA. Defaults to the Let's Encrypt
+	// production directory.
+	DirectoryURL string
+
+	// HTTPClient is used for requests to the ACME server, e.g. to trust
+	// the root of a test CA. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Email is the contact address of the ACME account, used for expiry
+	// and problem notices.
+	Email string
+
+	// Addr is the HTTPS address. Defaults to ":443", where the TLS-ALPN-01
+	// challenge is answered.
+	Addr string
+
+	// HTTPAddr, when set (usually ":80"), also starts a plain HTTP server
+	// that answers HTTP-01 challenges and redirects every other request to
+	// HTTPS.
+	HTTPAddr string
+}
+
+// StartAutoTLSWithConfig starts the server with certificates obtained
+// automatically as configured by cfg. It terminates the program if the
+// server fails like StartTLS.
+// Example:
+//
+//	server.StartAutoTLSWithConfig(falcon.AutoTLSConfig{
+//		Hosts:    []string{"example.com", "www.example.com"},
+//		Email:    "ops@example.com",
+//		CacheDir: "/var/lib/app/certs",
+//		HTTPAddr: ":80",
+//	})
+func (s *Server) StartAutoTLSWithConfig(cfg AutoTLSConfig) {
+	log.Printf("Starting server with AutoTLS on %s", strings.Join(cfg.Hosts, ", "))
+	if err := s.RunAutoTLSWithConfig(context.Background(), cfg); err != nil {
+		logFatal(err)
+	}
+}
+
+// RunAutoTLSWithConfig serves HTTPS with certificates obtained
+// automatically as configured by cfg, and the HTTP challenge and redirect
+// server when cfg.HTTPAddr is set, like Run. If either server fails, both
+// are shut down.
+func (s *Server) RunAutoTLSWithConfig(ctx context.Context, cfg AutoTLSConfig) error {
+	srv, manager, err := s.autoTLSServer(cfg)
+	if err != nil {
+		return err
+	}
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+
+	ln, err := listen(srv.Addr, true)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	if cfg.HTTPAddr == "" {
+		return s.serve(ctx, srv, ln, func() error { return srv.ServeTLS(ln, "", "") })
+	}
+
+	httpLn, err := listen(cfg.HTTPAddr, false)
+	if err != nil {
+		return err
+	}
+	defer httpLn.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	challenge := s.newHTTPServer(cfg.HTTPAddr)
+	challenge.Handler = manager.HTTPHandler(redirectToHTTPS(port))
+
+	// Whichever server stops first takes the other one down with it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		err := s.serve(ctx, challenge, httpLn, func() error { return challenge.Serve(httpLn) })
+		cancel()
+		errc <- err
+	}()
+	err = s.serve(ctx, srv, ln, func() error { return srv.ServeTLS(ln, "", "") })
+	cancel()
+	return errors.Join(err, <-errc)
+}
+
+// autoTLSServer returns the HTTPS server and certificate manager for cfg.
+func (s *Server) autoTLSServer(cfg AutoTLSConfig) (*http.Server, *autocert.Manager, error) {
+	if len(cfg.Hosts) == 0 {
+		return nil, nil, errors.New("falcon: AutoTLSConfig.Hosts is empty")
+	}
+	cache := cfg.Cache
+	if cache == nil {
+		dir := cfg.CacheDir
+		if dir == "" {
+			dir = "certs"
+		}
+		cache = autocert.DirCache(dir)
+	}
+	manager := &autocert.Manager{
+		Cache:      cache,
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Hosts...),
+		Email:      cfg.Email,
+	}
+	if cfg.DirectoryURL != "" || cfg.HTTPClient != nil {
+		manager.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL, HTTPClient: cfg.HTTPClient}
+	}
+
+	addr := cfg.Addr
+	if addr == "" {
+		addr = ":443"
+	}
+	srv := s.newHTTPServer(addr)
+	srv.TLSConfig = manager.TLSConfig()
+	srv.TLSConfig.MinVersion = tls.VersionTLS12
+	return srv, manager, nil
+}
+
+// redirectToHTTPS redirects requests to the same URL over HTTPS on port,
+// with 301 for GET and HEAD and 308 for other methods.
+func redirectToHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := hostname(r.Host)
+		switch {
+		case port != "" && port != "443":
+			host = net.JoinHostPort(host, port)
+		case strings.Contains(host, ":"):
+			host = "[" + host + "]"
+		}
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+	})
+}
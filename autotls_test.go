@@ -0,0 +1,213 @@
+package falcon
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// memCache is an in-memory autocert.Cache.
+type memCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (m *memCache) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.data[key]; ok {
+		return v, nil
+	}
+	return nil, autocert.ErrCacheMiss
+}
+
+func (m *memCache) Put(_ context.Context, key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[string][]byte)
+	}
+	m.data[key] = data
+	return nil
+}
+
+func (m *memCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+// cachedCert stores a long-lived self-signed certificate for host in the
+// autocert cache format, so handshakes never reach an ACME server.
+func cachedCert(t *testing.T, cache autocert.Cache, host string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{host},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	require.NoError(t, cache.Put(context.Background(), host, data))
+}
+
+func TestServer_AutoTLSServer(t *testing.T) {
+	s := New()
+
+	_, _, err := s.autoTLSServer(AutoTLSConfig{})
+	assert.Error(t, err)
+
+	srv, manager, err := s.autoTLSServer(AutoTLSConfig{Hosts: []string{"example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, ":443", srv.Addr)
+	assert.Equal(t, autocert.DirCache("certs"), manager.Cache)
+	assert.Nil(t, manager.Client, "Let's Encrypt production by default")
+	assert.Contains(t, srv.TLSConfig.NextProtos, "acme-tls/1", "TLS-ALPN-01 challenges are answered")
+
+	client := &http.Client{}
+	cache := &memCache{}
+	srv, manager, err = s.autoTLSServer(AutoTLSConfig{
+		Hosts:        []string{"example.com", "www.example.com"},
+		Cache:        cache,
+		CacheDir:     "ignored",
+		DirectoryURL: "https://localhost:14000/dir",
+		HTTPClient:   client,
+		Email:        "ops@example.com",
+		Addr:         ":8443",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ":8443", srv.Addr)
+	assert.Same(t, cache, manager.Cache)
+	assert.Equal(t, "https://localhost:14000/dir", manager.Client.DirectoryURL)
+	assert.Same(t, client, manager.Client.HTTPClient)
+	assert.Equal(t, "ops@example.com", manager.Email)
+	assert.NoError(t, manager.HostPolicy(context.Background(), "www.example.com"))
+	assert.Error(t, manager.HostPolicy(context.Background(), "evil.example.com"))
+
+	_, manager, err = s.autoTLSServer(AutoTLSConfig{Hosts: []string{"example.com"}, CacheDir: "/var/lib/certs"})
+	require.NoError(t, err)
+	assert.Equal(t, autocert.DirCache("/var/lib/certs"), manager.Cache)
+}
+
+func TestServer_RunAutoTLSWithConfig(t *testing.T) {
+	cache := &memCache{}
+	cachedCert(t, cache, "example.test")
+
+	s := New()
+	s.Configure(Config{ErrorLog: log.New(io.Discard, "", 0)})
+
+	_, stop := startServer(t, s, func(ctx context.Context) error {
+		return s.RunAutoTLSWithConfig(ctx, AutoTLSConfig{
+			Hosts:    []string{"example.test"},
+			Cache:    cache,
+			Addr:     "127.0.0.1:0",
+			HTTPAddr: "127.0.0.1:0",
+		})
+	})
+	assert.Eventually(t, func() bool { return len(s.Addrs()) == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	// Tell the HTTP server from the HTTPS one by its redirect. Connections
+	// are not reused: an idle one that never carried a request would hold
+	// up the graceful shutdown for several seconds.
+	plain := &http.Client{
+		Transport:     &http.Transport{DisableKeepAlives: true},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	send := func(method, addr, path string) *http.Response {
+		req, _ := http.NewRequest(method, "http://"+addr+path, nil)
+		req.Host = "example.test"
+		res, err := plain.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res
+	}
+	var httpAddr, tlsAddr string
+	for _, addr := range s.Addrs() {
+		if send(http.MethodGet, addr.String(), "/").StatusCode == http.StatusMovedPermanently {
+			httpAddr = addr.String()
+		} else {
+			tlsAddr = addr.String()
+		}
+	}
+	require.NotEmpty(t, httpAddr)
+	require.NotEmpty(t, tlsAddr)
+	_, port, _ := net.SplitHostPort(tlsAddr)
+
+	res := send(http.MethodGet, httpAddr, "/docs?page=2")
+	assert.Equal(t, "https://example.test:"+port+"/docs?page=2", res.Header.Get("Location"))
+	res = send(http.MethodPost, httpAddr, "/orders")
+	assert.Equal(t, http.StatusPermanentRedirect, res.StatusCode)
+	res = send(http.MethodGet, httpAddr, "/.well-known/acme-challenge/unknown-token")
+	assert.Equal(t, http.StatusNotFound, res.StatusCode, "challenges are not redirected")
+
+	// Certificates come from the cache for allowed hosts only
+	conn, err := tls.Dial("tcp", tlsAddr, &tls.Config{ServerName: "example.test", InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.Equal(t, "example.test", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+	conn.Close()
+	_, err = tls.Dial("tcp", tlsAddr, &tls.Config{ServerName: "other.test", InsecureSkipVerify: true})
+	assert.Error(t, err)
+
+	assert.NoError(t, stop())
+}
+
+func TestServer_RunAutoTLSWithConfigHTTPAddrInUse(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+
+	s := New()
+	err = s.RunAutoTLSWithConfig(context.Background(), AutoTLSConfig{
+		Hosts:    []string{"example.test"},
+		Cache:    &memCache{},
+		Addr:     "127.0.0.1:0",
+		HTTPAddr: taken.Addr().String(),
+	})
+	assert.Error(t, err)
+	assert.Empty(t, s.Addrs(), "the HTTPS listener is closed again")
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct{ port, host, want string }{
+		{"443", "example.com", "https://example.com/a?b=1"},
+		{"443", "example.com:80", "https://example.com/a?b=1"},
+		{"8443", "example.com:8080", "https://example.com:8443/a?b=1"},
+		{"443", "[::1]:80", "https://[::1]/a?b=1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://"+tt.host+"/a?b=1", nil)
+		rec := httptest.NewRecorder()
+		redirectToHTTPS(tt.port).ServeHTTP(rec, req)
+		assert.Equal(t, tt.want, rec.Header().Get("Location"), tt.host)
+	}
+}
@@ -0,0 +1,101 @@
+package falcon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CertReloader serves a certificate and key pair from files and reloads
+// it when the files change, e.g. when cert-manager rotates a mounted
+// secret, so connections pick up the new certificate without a restart.
+// Use its GetCertificate method in a tls.Config.
+type CertReloader struct {
+	certFile, keyFile string
+	cert              atomic.Pointer[tls.Certificate]
+
+	mu              sync.Mutex // serializes Reload
+	certPEM, keyPEM []byte     // contents of the loaded pair
+}
+
+// NewCertReloader loads the pair in certFile and keyFile. It fails if the
+// pair cannot be loaded.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate. It has the signature of
+// tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload reads the files again and swaps in the pair if it changed. If the
+// new pair is invalid, for instance because only one of the files has
+// been replaced so far, the current certificate is kept and the error
+// returned. changed reports whether a new certificate was swapped in.
+func (r *CertReloader) Reload() (changed bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM) {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, err
+	}
+	r.cert.Store(&cert)
+	r.certPEM, r.keyPEM = certPEM, keyPEM
+	return true, nil
+}
+
+// Watch calls Reload every interval until ctx is done, logging reloads
+// and failures to logger, or the standard logger when nil. The same
+// failure is only logged once in a row.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration, logger *log.Logger) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastErr := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := r.Reload()
+		switch {
+		case err != nil:
+			if err.Error() != lastErr {
+				logger.Printf("falcon: reloading certificate %s: %v (keeping the current one)", r.certFile, err)
+				lastErr = err.Error()
+			}
+			continue
+		case changed:
+			logger.Printf("falcon: reloaded certificate %s", r.certFile)
+		}
+		lastErr = ""
+	}
+}
@@ -0,0 +1,119 @@
+package falcon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func currentCN(t *testing.T, r *CertReloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSignedCert(t, dir, "first")
+
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "first", currentCN(t, r))
+
+	changed, err := r.Reload()
+	assert.NoError(t, err)
+	assert.False(t, changed, "unchanged files")
+
+	writeSelfSignedCert(t, dir, "second")
+	changed, err = r.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "second", currentCN(t, r))
+
+	// A half-written or mismatched pair keeps the current certificate
+	key, _ := os.ReadFile(keyFile)
+	writeSelfSignedCert(t, dir, "third")
+	require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+	_, err = r.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, "second", currentCN(t, r))
+
+	require.NoError(t, os.Remove(keyFile))
+	_, err = r.Reload()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Equal(t, "second", currentCN(t, r))
+
+	_, err = NewCertReloader(certFile, keyFile)
+	assert.Error(t, err)
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use by a logger and a
+// test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServer_RunTLSWithConfigReloads(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSignedCert(t, dir, "before")
+
+	var logs syncBuffer
+	s := New()
+	s.Configure(Config{ErrorLog: log.New(&logs, "", 0)})
+
+	addr, stop := startServer(t, s, func(ctx context.Context) error {
+		return s.RunTLSWithConfig(ctx, "127.0.0.1:0", TLSConfig{
+			CertFile:       certFile,
+			KeyFile:        keyFile,
+			ReloadInterval: 10 * time.Millisecond,
+		})
+	})
+
+	servedCN := func() string {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return err.Error()
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}
+	assert.Equal(t, "before", servedCN())
+
+	writeSelfSignedCert(t, dir, "after")
+	assert.Eventually(t, func() bool { return servedCN() == "after" }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return strings.Contains(logs.String(), "reloaded certificate") }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	assert.Eventually(t, func() bool { return strings.Contains(logs.String(), "keeping the current one") }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "after", servedCN())
+
+	assert.NoError(t, stop())
+}
+
+func TestServer_RunTLSWithConfigRequiresCertificate(t *testing.T) {
+	err := New().RunTLSWithConfig(context.Background(), "127.0.0.1:0", TLSConfig{CertFile: "missing.pem", KeyFile: "missing.key"})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,121 @@
+package falcon
+
+import "github.com/ascendingheavens/falcon/middleware"
+
+// routeEntry ties a registered route to the group it was registered
+// through, so its middleware chain can be built when serving starts.
+type routeEntry struct {
+	route *Route
+	group *Group // nil for routes registered directly on the Server
+}
+
+// chains holds the handlers requests are dispatched to: every route and
+// fallback wrapped in its middleware. A snapshot is built once and never
+// modified; registering routes or middleware afterwards replaces it.
+type chains struct {
+	routes           map[*Route]HandlerFunc
+	notFound         HandlerFunc
+	methodNotAllowed HandlerFunc
+	options          HandlerFunc
+
+	// Group overrides of notFound and methodNotAllowed, already wrapped
+	// in the group middleware. Every group gets its own options, so
+	// preflight requests pass through group middleware such as CORS.
+	groupNotFound         map[*Group]HandlerFunc
+	groupMethodNotAllowed map[*Group]HandlerFunc
+	groupOptions          map[*Group]HandlerFunc
+}
+
+// compiled returns the current chains, building them if this is the first
+// request or something was registered since the last build.
+func (s *Server) compiled() *chains {
+	if ch := s.chains.Load(); ch != nil {
+		return ch
+	}
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	if ch := s.chains.Load(); ch != nil {
+		return ch
+	}
+	ch := s.build()
+	s.chains.Store(ch)
+	return ch
+}
+
+// invalidate discards the built chains so the next request rebuilds them
+// with the routes and middleware registered so far.
+func (s *Server) invalidate() {
+	s.chains.Store(nil)
+}
+
+// build wraps every route and fallback handler in its middleware.
+func (s *Server) build() *chains {
+	ch := &chains{
+		routes:                make(map[*Route]HandlerFunc, len(s.routes)),
+		notFound:              s.wrap(orDefault(s.notFound, defaultNotFound), nil, nil),
+		methodNotAllowed:      s.wrap(orDefault(s.methodNotAllowed, defaultMethodNotAllowed), nil, nil),
+		options:               s.wrap(automaticOptions, nil, nil),
+		groupNotFound:         make(map[*Group]HandlerFunc),
+		groupMethodNotAllowed: make(map[*Group]HandlerFunc),
+		groupOptions:          make(map[*Group]HandlerFunc, len(s.groups)),
+	}
+	for _, e := range s.routes {
+		ch.routes[e.route] = s.wrap(e.route.Handler, e.group, e.route)
+	}
+	for _, g := range s.groups {
+		if g.notFound != nil {
+			ch.groupNotFound[g] = s.wrap(g.notFound, g, nil)
+		}
+		if g.methodNotAllowed != nil {
+			ch.groupMethodNotAllowed[g] = s.wrap(g.methodNotAllowed, g, nil)
+		}
+		ch.groupOptions[g] = s.wrap(automaticOptions, g, nil)
+	}
+	return ch
+}
+
+// wrap applies the middleware of the server and of g (which may be nil)
+// to h, the handler of rt or, when rt is nil, a fallback. Requests pass
+// through them in a fixed order regardless of when each was registered:
+// global, then conditional, then each enclosing group from the outermost
+// to g, then h.
+func (s *Server) wrap(h HandlerFunc, g *Group, rt *Route) HandlerFunc {
+	mws := s.middlewareFor(g, rt)
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// middlewareFor lists the middleware wrapping rt, registered through g,
+// outermost first. Conditional middleware that can never match rt is left
+// out, and middleware that always matches it is applied unguarded.
+func (s *Server) middlewareFor(g *Group, rt *Route) []middleware.Middleware {
+	mws := make([]middleware.Middleware, 0, len(s.middlewares)+len(s.conditionalMiddleware))
+	mws = append(mws, s.middlewares...)
+	for _, c := range s.conditionalMiddleware {
+		if rt == nil {
+			mws = append(mws, c.guard())
+			continue
+		}
+		switch always, never := c.forRoute(rt); {
+		case never:
+		case always:
+			mws = append(mws, c.mw)
+		default:
+			mws = append(mws, c.guard())
+		}
+	}
+	if g != nil {
+		mws = append(mws, g.chain()...)
+	}
+	return mws
+}
+
+// orDefault returns h, or def when h is nil.
+func orDefault(h, def HandlerFunc) HandlerFunc {
+	if h == nil {
+		return def
+	}
+	return h
+}
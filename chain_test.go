@@ -0,0 +1,92 @@
+package falcon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// traceMiddleware appends name to the X-Trace header, recording the order
+// in which middleware runs.
+func traceMiddleware(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) *Response {
+			c.Writer.Header().Add("X-Trace", name)
+			return next(c)
+		}
+	}
+}
+
+func trace(rec *httptest.ResponseRecorder) string {
+	return strings.Join(rec.Header().Values("X-Trace"), ",")
+}
+
+func TestServer_MiddlewareOrderIsIndependentOfRegistration(t *testing.T) {
+	s := New()
+	ok := func(c *Context) *Response { return &Response{Success: true, Code: http.StatusOK} }
+
+	// Routes first, middleware afterwards, groups out of order
+	api := s.Group("/api")
+	v1 := api.Group("/v1")
+	v1.GET("/users", ok)
+	s.GET("/health", ok)
+
+	v1.Use(traceMiddleware("v1"))
+	s.UseIf("/api/*", traceMiddleware("conditional"))
+	api.Use(traceMiddleware("api"))
+	s.Use(traceMiddleware("global"))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "global,conditional,api,v1", trace(rec))
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, "global", trace(rec))
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "global,conditional", trace(rec))
+}
+
+func TestServer_RegistrationAfterServingRebuildsChains(t *testing.T) {
+	s := New()
+	s.GET("/a", func(c *Context) *Response { return &Response{Success: true, Code: http.StatusOK} })
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/a", nil))
+	assert.Empty(t, trace(rec))
+
+	s.Use(traceMiddleware("late"))
+	s.GET("/b", func(c *Context) *Response { return &Response{Success: true, Code: http.StatusOK} })
+
+	for _, path := range []string{"/a", "/b"} {
+		rec = httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, "late", trace(rec), path)
+	}
+}
+
+func TestServer_ChainsAreBuiltOnce(t *testing.T) {
+	s := New()
+	built := 0
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		built++
+		return next
+	})
+	s.GET("/a", func(c *Context) *Response { return &Response{Success: true, Code: http.StatusOK} })
+
+	for i := 0; i < 3; i++ {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	}
+	// One chain for the route and one each for NotFound, MethodNotAllowed
+	// and automatic OPTIONS
+	assert.Equal(t, 4, built)
+}
@@ -0,0 +1,162 @@
+package falcon
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ascendingheavens/falcon/middleware"
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// condition is a ConditionalMiddleware compiled once at registration.
+type condition struct {
+	mw      middleware.Middleware
+	pattern []string // glob segments; nil matches every path
+	re      *regexp.Regexp
+	methods []string // upper-case methods; nil matches every method
+	hosts   []string // lower-case host globs; nil matches every host
+	match   func(*http.Request) bool
+}
+
+// compileCondition validates cm and prepares it for matching.
+func compileCondition(cm middleware.ConditionalMiddleware) (*condition, error) {
+	if cm.Middleware == nil {
+		return nil, fmt.Errorf("conditional middleware for %q has no Middleware", cm.Pattern)
+	}
+	c := &condition{mw: cm.Middleware, re: cm.Regexp, match: cm.Match}
+
+	if p := strings.Trim(cm.Pattern, "/"); p != "" {
+		c.pattern = strings.Split(p, "/")
+		for _, seg := range c.pattern {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", cm.Pattern, err)
+			}
+		}
+	}
+	for _, m := range cm.Methods {
+		c.methods = append(c.methods, strings.ToUpper(m))
+	}
+	for _, h := range cm.Hosts {
+		h = strings.ToLower(h)
+		if _, err := path.Match(h, ""); err != nil {
+			return nil, fmt.Errorf("invalid host pattern %q: %w", h, err)
+		}
+		c.hosts = append(c.hosts, h)
+	}
+	return c, nil
+}
+
+// forRoute decides at startup whether c applies to requests for rt.
+// never means the middleware can be left out of the route's chain and
+// always that it can be applied without checking; otherwise the request
+// has to be checked. Paths are only known up front for routes without
+// parameters, and hosts and predicates are only known per request.
+func (c *condition) forRoute(rt *Route) (always, never bool) {
+	if !c.matchMethod(rt.Method) {
+		return false, true
+	}
+	if !rt.IsStatic() {
+		return false, false
+	}
+	if !c.matchPath(rt.Path) {
+		return false, true
+	}
+	return c.hosts == nil && c.match == nil, false
+}
+
+// matchRequest reports whether c applies to r.
+func (c *condition) matchRequest(r *http.Request) bool {
+	return c.matchMethod(r.Method) &&
+		c.matchPath(r.URL.Path) &&
+		c.matchHost(r.Host) &&
+		(c.match == nil || c.match(r))
+}
+
+// matchMethod reports whether method passes the method filter. HEAD
+// requests are answered by GET routes, so a filter allowing GET allows
+// HEAD as well.
+func (c *condition) matchMethod(method string) bool {
+	if c.methods == nil {
+		return true
+	}
+	return slices.Contains(c.methods, method) ||
+		(method == http.MethodHead && slices.Contains(c.methods, http.MethodGet))
+}
+
+// matchPath reports whether p passes the glob and the regular expression.
+func (c *condition) matchPath(p string) bool {
+	if c.re != nil && !c.re.MatchString(p) {
+		return false
+	}
+	return matchGlob(c.pattern, p)
+}
+
+// matchHost reports whether host, ignoring any port, matches one of the
+// host globs.
+func (c *condition) matchHost(host string) bool {
+	if c.hosts == nil {
+		return true
+	}
+	host = hostname(host)
+	for _, pattern := range c.hosts {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether the glob segments match p or a path p lies
+// below. p is a request path starting with '/', or "" once every segment
+// has been consumed.
+func matchGlob(pattern []string, p string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		if matchGlob(pattern[1:], p) {
+			return true
+		}
+		if p == "" {
+			return false
+		}
+		_, rest := nextSegment(p)
+		return matchGlob(pattern, rest)
+	}
+	if p == "" {
+		return false
+	}
+	seg, rest := nextSegment(p)
+	if ok, _ := path.Match(pattern[0], seg); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], rest)
+}
+
+// nextSegment splits the first segment off a path starting with '/'.
+func nextSegment(p string) (seg, rest string) {
+	p = p[1:]
+	if i := strings.IndexByte(p, '/'); i >= 0 {
+		return p[:i], p[i:]
+	}
+	return p, ""
+}
+
+// guard turns c into a middleware that runs c.mw only for matching
+// requests. Both branches are built up front, so nothing is wrapped per
+// request.
+func (c *condition) guard() middleware.Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		wrapped := c.mw(next)
+		return func(ctx *server.Context) *server.Response {
+			if c.matchRequest(ctx.Request) {
+				return wrapped(ctx)
+			}
+			return next(ctx)
+		}
+	}
+}
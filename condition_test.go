@@ -0,0 +1,133 @@
+package falcon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCondition_PathGlobs(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/api", "/api", true},
+		{"/api", "/api/users", true},
+		{"/api", "/apiary", false},
+		{"/api/", "/api", true},
+		{"/api/*", "/api", false},
+		{"/api/*", "/api/users", true},
+		{"/api/*", "/api/users/1", true},
+		{"/api/*/admin/**", "/api/v1/admin", true},
+		{"/api/*/admin/**", "/api/v1/admin/users/1", true},
+		{"/api/*/admin/**", "/api/v1/v2/admin", false},
+		{"/api/**/export", "/api/export", true},
+		{"/api/**/export", "/api/a/b/export", true},
+		{"/api/**/export", "/api/a/b/exports", false},
+		{"/files/*.json", "/files/data.json", true},
+		{"/files/*.json", "/files/data.xml", false},
+		{"", "/anything", true},
+		{"/", "/anything", true},
+	}
+	for _, tt := range tests {
+		c, err := compileCondition(ConditionalMiddleware{Pattern: tt.pattern, Middleware: headerMiddleware("X")})
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.want, c.matchPath(tt.path), "%s vs %s", tt.pattern, tt.path)
+		}
+	}
+}
+
+func TestCondition_Filters(t *testing.T) {
+	c, err := compileCondition(ConditionalMiddleware{
+		Regexp:     regexp.MustCompile(`^/reports/\d+$`),
+		Methods:    []string{"get", http.MethodPost},
+		Hosts:      []string{"*.example.com"},
+		Match:      func(r *http.Request) bool { return r.Header.Get("X-Beta") == "1" },
+		Middleware: headerMiddleware("X"),
+	})
+	assert.NoError(t, err)
+
+	req := func(method, target, host string, beta bool) *http.Request {
+		r := httptest.NewRequest(method, target, nil)
+		r.Host = host
+		if beta {
+			r.Header.Set("X-Beta", "1")
+		}
+		return r
+	}
+
+	assert.True(t, c.matchRequest(req(http.MethodGet, "/reports/7", "app.example.com", true)))
+	assert.True(t, c.matchRequest(req(http.MethodHead, "/reports/7", "APP.example.com:8080", true)))
+	assert.False(t, c.matchRequest(req(http.MethodDelete, "/reports/7", "app.example.com", true)))
+	assert.False(t, c.matchRequest(req(http.MethodGet, "/reports/x", "app.example.com", true)))
+	assert.False(t, c.matchRequest(req(http.MethodGet, "/reports/7", "example.org", true)))
+	assert.False(t, c.matchRequest(req(http.MethodGet, "/reports/7", "app.example.com", false)))
+}
+
+func TestCondition_InvalidPatterns(t *testing.T) {
+	_, err := compileCondition(ConditionalMiddleware{Pattern: "/api/[", Middleware: headerMiddleware("X")})
+	assert.Error(t, err)
+	_, err = compileCondition(ConditionalMiddleware{Pattern: "/api"})
+	assert.Error(t, err)
+
+	s := New()
+	assert.Panics(t, func() { s.UseIf("/files/[a-", headerMiddleware("X")) })
+}
+
+func TestCondition_MatchDoesNotAllocate(t *testing.T) {
+	c, err := compileCondition(ConditionalMiddleware{Pattern: "/api/*/admin/**", Middleware: headerMiddleware("X")})
+	assert.NoError(t, err)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users/1", nil)
+
+	allocs := testing.AllocsPerRun(100, func() { c.matchRequest(r) })
+	assert.Zero(t, allocs)
+}
+
+func TestServer_UseIfIsSegmentAware(t *testing.T) {
+	s := New()
+	s.UseIf("/api", headerMiddleware("X-API"))
+	ok := func(c *Context) *Response { return &Response{Success: true, Code: http.StatusOK} }
+	s.GET("/api", ok)
+	s.GET("/api/users/:id", ok)
+	s.GET("/apiary", ok)
+
+	for path, want := range map[string]string{"/api": "1", "/api/users/1": "1", "/apiary": ""} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, want, rec.Header().Get("X-API"), path)
+	}
+}
+
+func TestServer_UseWhenIsResolvedPerRoute(t *testing.T) {
+	s := New()
+	applied := 0
+	s.UseWhen(ConditionalMiddleware{
+		Pattern: "/admin/**",
+		Methods: []string{http.MethodPost},
+		Middleware: func(next HandlerFunc) HandlerFunc {
+			applied++
+			return next
+		},
+	})
+	ok := func(c *Context) *Response { return &Response{Success: true, Code: http.StatusOK} }
+	s.GET("/admin/users", ok)
+	s.POST("/admin/users", ok)
+	s.POST("/public", ok)
+	s.POST("/admin/users/:id", ok)
+
+	routes := s.Routes()
+	assert.Equal(t, 0, routes[0].Middlewares) // wrong method
+	assert.Equal(t, 1, routes[1].Middlewares) // always applies
+	assert.Equal(t, 0, routes[2].Middlewares) // wrong path
+	assert.Equal(t, 1, routes[3].Middlewares) // checked per request
+
+	// Only the two routes that may match, plus the three fallbacks whose
+	// paths are unknown, get the middleware at all
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/admin/users", nil))
+	assert.Equal(t, 5, applied)
+}
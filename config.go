@@ -0,0 +1,138 @@
+package falcon
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Default values used for the zero fields of Config. They bound how long a
+// client may take to send a request and to read the response, so that slow
+// clients (slowloris) cannot hold connections open indefinitely.
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 60 * time.Second
+	DefaultWriteTimeout      = 60 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultMaxHeaderBytes    = 1 << 20 // 1 MB
+)
+
+// Config holds the settings of the http.Server created by Run, Start and
+// their TLS variants. Zero fields use the defaults above; set a duration
+// to a negative value to disable that timeout.
+type Config struct {
+	// ReadHeaderTimeout is how long a client may take to send the request
+	// headers. Defaults to DefaultReadHeaderTimeout.
+	ReadHeaderTimeout time.Duration
+
+	// ReadTimeout is how long a client may take to send the whole request,
+	// body included. Defaults to DefaultReadTimeout.
+	ReadTimeout time.Duration
+
+	// WriteTimeout is how long writing the response may take, counted from
+	// the end of the request headers. Raise or disable it for long
+	// downloads and streaming responses. Defaults to DefaultWriteTimeout.
+	WriteTimeout time.Duration
+
+	// IdleTimeout is how long a keep-alive connection may wait for the
+	// next request. Defaults to DefaultIdleTimeout.
+	IdleTimeout time.Duration
+
+	// MaxHeaderBytes limits the size of the request line and headers.
+	// Defaults to DefaultMaxHeaderBytes.
+	MaxHeaderBytes int
+
+	// ShutdownTimeout is how long Run waits for in-flight requests once
+	// its context is done or a shutdown signal arrives. Defaults to
+	// DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+
+	// ShutdownDelay keeps the listeners open this long after Shutdown
+	// begins while the readiness endpoint already fails, so load
+	// balancers stop routing new requests first. It counts towards the
+	// shutdown deadline. Zero closes them at once.
+	ShutdownDelay time.Duration
+
+	// ErrorLog receives errors from accepting connections and from
+	// handlers the http package recovers. Defaults to the log package's
+	// standard logger.
+	ErrorLog *log.Logger
+
+	// ConnState is called when a client connection changes state, see
+	// http.Server.ConnState.
+	ConnState func(net.Conn, http.ConnState)
+
+	// BaseContext returns the base context for requests on a listener,
+	// see http.Server.BaseContext.
+	BaseContext func(net.Listener) context.Context
+}
+
+// Configure sets the http.Server settings used by every server started
+// afterwards.
+// Example:
+//
+//	app.Configure(falcon.Config{
+//		WriteTimeout: -1, // streaming responses
+//		ErrorLog:     log.New(os.Stderr, "http: ", log.LstdFlags),
+//	})
+func (s *Server) Configure(cfg Config) {
+	s.life.mu.Lock()
+	s.config = cfg
+	s.life.mu.Unlock()
+}
+
+// Config returns the settings servers are started with, defaults included.
+func (s *Server) Config() Config {
+	s.life.mu.Lock()
+	defer s.life.mu.Unlock()
+	return s.config.withDefaults()
+}
+
+// withDefaults returns cfg with its zero fields set to the defaults.
+func (cfg Config) withDefaults() Config {
+	if cfg.ReadHeaderTimeout == 0 {
+		cfg.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if cfg.ReadTimeout == 0 {
+		cfg.ReadTimeout = DefaultReadTimeout
+	}
+	if cfg.WriteTimeout == 0 {
+		cfg.WriteTimeout = DefaultWriteTimeout
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+	if cfg.MaxHeaderBytes == 0 {
+		cfg.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	return cfg
+}
+
+// newHTTPServer returns an http.Server for addr that serves s with the
+// configured settings.
+func (s *Server) newHTTPServer(addr string) *http.Server {
+	cfg := s.Config()
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: enabled(cfg.ReadHeaderTimeout),
+		ReadTimeout:       enabled(cfg.ReadTimeout),
+		WriteTimeout:      enabled(cfg.WriteTimeout),
+		IdleTimeout:       enabled(cfg.IdleTimeout),
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          cfg.ErrorLog,
+		ConnState:         cfg.ConnState,
+		BaseContext:       cfg.BaseContext,
+	}
+}
+
+// enabled maps a negative (disabled) timeout to the zero value, which
+// http.Server treats as no timeout.
+func enabled(d time.Duration) time.Duration {
+	return max(d, 0)
+}
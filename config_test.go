@@ -0,0 +1,128 @@
+package falcon
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ConfigDefaults(t *testing.T) {
+	srv := New().newHTTPServer(":8080")
+	assert.Equal(t, ":8080", srv.Addr)
+	assert.Equal(t, DefaultReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, DefaultReadTimeout, srv.ReadTimeout)
+	assert.Equal(t, DefaultWriteTimeout, srv.WriteTimeout)
+	assert.Equal(t, DefaultIdleTimeout, srv.IdleTimeout)
+	assert.Equal(t, DefaultMaxHeaderBytes, srv.MaxHeaderBytes)
+	assert.Equal(t, DefaultShutdownTimeout, New().Config().ShutdownTimeout)
+}
+
+func TestServer_Configure(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	s := New()
+	s.Configure(Config{
+		ReadTimeout:    5 * time.Second,
+		WriteTimeout:   -1,
+		MaxHeaderBytes: 4096,
+		ErrorLog:       logger,
+	})
+
+	srv := s.newHTTPServer(":8080")
+	assert.Equal(t, 5*time.Second, srv.ReadTimeout)
+	assert.Zero(t, srv.WriteTimeout, "negative disables the timeout")
+	assert.Equal(t, DefaultReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, 4096, srv.MaxHeaderBytes)
+	assert.Same(t, logger, srv.ErrorLog)
+	assert.Equal(t, http.Handler(s), srv.Handler)
+
+	// AutoTLS servers share the settings
+	srv, _, err := s.autoTLSServer(AutoTLSConfig{Hosts: []string{"example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, srv.ReadTimeout)
+}
+
+func TestServer_ReadHeaderTimeoutClosesSlowClients(t *testing.T) {
+	var logs bytes.Buffer
+	s := New()
+	s.Configure(Config{ReadHeaderTimeout: 100 * time.Millisecond, ErrorLog: log.New(&logs, "", 0)})
+
+	addr, _ := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\nX-Slow: "))
+	require.NoError(t, err)
+
+	// The server gives up on the unfinished headers and closes the connection
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	begin := time.Now()
+	_, err = io.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(begin), 2*time.Second)
+}
+
+type ctxKey struct{}
+
+func TestServer_ConnStateAndBaseContext(t *testing.T) {
+	var mu sync.Mutex
+	var states []http.ConnState
+
+	s := New()
+	s.Configure(Config{
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			mu.Lock()
+			states = append(states, state)
+			mu.Unlock()
+		},
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), ctxKey{}, "base")
+		},
+	})
+	s.GET("/", func(c *Context) *Response {
+		v, _ := c.Request.Context().Value(ctxKey{}).(string)
+		return c.String(http.StatusOK, v)
+	})
+
+	addr, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	res, err := http.Get("http://" + addr + "/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "base", string(body))
+
+	assert.NoError(t, stop())
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, states, http.StateNew)
+	assert.Contains(t, states, http.StateActive)
+}
+
+func TestServer_RunUsesShutdownTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	s := blockingServer(started, release)
+	s.Configure(Config{ShutdownTimeout: 50 * time.Millisecond})
+
+	addr, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	go func() {
+		if res, err := http.Get("http://" + addr + "/slow"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	<-started
+
+	// stop fails the test if Run ignores the shutdown timeout
+	assert.ErrorIs(t, stop(), context.DeadlineExceeded)
+}
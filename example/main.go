@@ -2,6 +2,7 @@ package main
 
 import (
 	"net/http"
+	"os"
 
 	falcon "github.com/ascendingheavens/falcon"
 	"github.com/ascendingheavens/falcon/middleware"
@@ -59,7 +60,10 @@ func main() {
 		}
 	})
 
-	// Start server
+	// Print the route table and start the server; Ctrl-C or SIGTERM
+	// drains in-flight requests before exiting
+	_ = app.PrintRoutes(os.Stdout)
+	app.ShutdownOnSignal()
 	app.Start(":8080")
 }
 
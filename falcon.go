@@ -1,16 +1,29 @@
 package falcon
 
 import (
+	"context"
 	"encoding/json"
 	"html/template"
 	"log"
 	"net/http"
-	"strings"
 
 	"github.com/ascendingheavens/falcon/middleware"
 	"github.com/ascendingheavens/falcon/server"
 )
 
+// anyMethods lists the HTTP methods registered by Any.
+var anyMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
 // New creates a new Falcon Server instance with an empty router and middleware stack.
 // Example:
 //
@@ -22,107 +35,246 @@ func New() *Server {
 	}
 }
 
-// Use registers a global middleware that will run on every request.
+// Use registers a global middleware that will run on every request,
+// including routes registered before the call and the fallback handlers.
 // Middleware is executed in the order it is added.
 // Example:
 //
 //	server.Use(middleware.CORS())
 func (s *Server) Use(mw middleware.Middleware) {
 	s.middlewares = append(s.middlewares, mw)
+	s.invalidate()
 }
 
 // UseIf registers a conditional middleware that only runs if the request path
-// matches the given pattern. Patterns can include a wildcard '*' at the end.
+// matches the given pattern, a segment-aware glob: "/api" covers "/api" and
+// everything below it but not "/apiary", "*" matches one segment and "**"
+// any number of them. See middleware.ConditionalMiddleware.
 // Example: UseIf("/api/v1/*", AuthMiddleware())
 func (s *Server) UseIf(pattern string, mw middleware.Middleware) {
-	s.conditionalMiddleware = append(s.conditionalMiddleware, middleware.ConditionalMiddleware{
-		Pattern:    pattern,
-		Middleware: mw,
-	})
+	s.UseWhen(middleware.ConditionalMiddleware{Pattern: pattern, Middleware: mw})
+}
+
+// UseWhen registers a conditional middleware that only runs for requests
+// matching every filter set on cm: path glob, regular expression, methods,
+// hosts and predicate. It runs after the global middleware and before any
+// group middleware. Matching is decided once per route when the server
+// starts where possible, so static routes pay nothing per request.
+// UseWhen panics if the pattern is malformed.
+// Example:
+//
+//	app.UseWhen(falcon.ConditionalMiddleware{
+//		Pattern:    "/api/**",
+//		Methods:    []string{http.MethodPost, http.MethodPut, http.MethodDelete},
+//		Middleware: AuditMiddleware(),
+//	})
+func (s *Server) UseWhen(cm middleware.ConditionalMiddleware) {
+	c, err := compileCondition(cm)
+	if err != nil {
+		panic("falcon: " + err.Error())
+	}
+	s.conditionalMiddleware = append(s.conditionalMiddleware, c)
+	s.invalidate()
 }
 
 // Handle registers a route with a specific HTTP method and path.
-// Global middleware is automatically applied in reverse order (so execution order is correct).
-func (s *Server) Handle(method, path string, handler server.HandlerFunc) {
-	combined := handler
-	for i := len(s.middlewares) - 1; i >= 0; i-- {
-		combined = s.middlewares[i](combined)
+// Middleware is applied when the server starts serving, so it wraps the
+// route no matter whether Use is called before or after Handle.
+// The returned Route can be named for reverse URL generation:
+//
+//	app.GET("/users/:id", showUser).Name("user.show")
+//
+// Handle panics if the path is malformed or conflicts with a registered
+// route, so a broken route table fails at startup. Use TryHandle to get
+// the error instead.
+func (s *Server) Handle(method, path string, handler server.HandlerFunc) *Route {
+	rt, err := s.TryHandle(method, path, handler)
+	if err != nil {
+		panic("falcon: " + err.Error())
+	}
+	return rt
+}
+
+// TryHandle registers a route like Handle but returns an error instead of
+// panicking when the path is malformed (server.ErrInvalidRoute) or conflicts
+// with a registered route (server.ErrRouteConflict), e.g. the same method
+// and path twice or "/users/:id" next to "/users/:name".
+func (s *Server) TryHandle(method, path string, handler server.HandlerFunc) (*Route, error) {
+	return s.register(method, path, handler, nil)
+}
+
+// register adds the route to the router of g (the server router unless g
+// belongs to a Host) and records the group it was registered through.
+func (s *Server) register(method, path string, handler HandlerFunc, g *Group) (*Route, error) {
+	rt, err := s.routerFor(g).TryHandle(method, path, handler)
+	if err != nil {
+		return nil, err
 	}
-	s.router.Handle(method, path, combined)
+	s.routes = append(s.routes, routeEntry{route: rt, group: g})
+	s.invalidate()
+	return rt, nil
 }
 
 // GET registers a route with the HTTP GET method on the server.
 // The handler is invoked when a request matches the given path.
-func (s *Server) GET(path string, handler server.HandlerFunc) {
-	s.Handle(http.MethodGet, path, handler)
+func (s *Server) GET(path string, handler server.HandlerFunc) *Route {
+	return s.Handle(http.MethodGet, path, handler)
 }
 
 // POST registers a route with the HTTP POST method on the server.
 // The handler is invoked when a request matches the given path.
-func (s *Server) POST(path string, handler server.HandlerFunc) {
-	s.Handle(http.MethodPost, path, handler)
+func (s *Server) POST(path string, handler server.HandlerFunc) *Route {
+	return s.Handle(http.MethodPost, path, handler)
 }
 
 // PUT registers a route with the HTTP PUT method on the server.
 // The handler is invoked when a request matches the given path.
-func (s *Server) PUT(path string, handler server.HandlerFunc) {
-	s.Handle(http.MethodPut, path, handler)
+func (s *Server) PUT(path string, handler server.HandlerFunc) *Route {
+	return s.Handle(http.MethodPut, path, handler)
 }
 
 // PATCH registers a route with the HTTP PATCH method on the server.
 // The handler is invoked when a request matches the given path.
-func (s *Server) PATCH(path string, handler server.HandlerFunc) {
-	s.Handle(http.MethodPatch, path, handler)
+func (s *Server) PATCH(path string, handler server.HandlerFunc) *Route {
+	return s.Handle(http.MethodPatch, path, handler)
 }
 
 // DELETE registers a route with the HTTP DELETE method on the server.
 // The handler is invoked when a request matches the given path.
-func (s *Server) DELETE(path string, handler server.HandlerFunc) {
-	s.Handle(http.MethodDelete, path, handler)
+func (s *Server) DELETE(path string, handler server.HandlerFunc) *Route {
+	return s.Handle(http.MethodDelete, path, handler)
+}
+
+// HEAD registers a route with the HTTP HEAD method on the server.
+// Without it, HEAD requests are answered by the matching GET route
+// with the body discarded.
+func (s *Server) HEAD(path string, handler server.HandlerFunc) *Route {
+	return s.Handle(http.MethodHead, path, handler)
+}
+
+// OPTIONS registers a route with the HTTP OPTIONS method on the server.
+// Without it, OPTIONS requests are answered with 204 and an Allow header.
+func (s *Server) OPTIONS(path string, handler server.HandlerFunc) *Route {
+	return s.Handle(http.MethodOptions, path, handler)
+}
+
+// Any registers the handler for every standard HTTP method on the given path.
+func (s *Server) Any(path string, handler server.HandlerFunc) []*Route {
+	return s.Match(anyMethods, path, handler)
+}
+
+// Match registers the handler for each of the given HTTP methods on the path.
+// Example: app.Match([]string{"GET", "POST"}, "/login", loginHandler)
+func (s *Server) Match(methods []string, path string, handler server.HandlerFunc) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, m := range methods {
+		routes = append(routes, s.Handle(m, path, handler))
+	}
+	return routes
+}
+
+// URL builds the path of the route registered under name, filling in its
+// parameters from key/value pairs. Values are escaped, and an error is
+// returned for unknown names and for missing or unknown parameters.
+// Routes registered through Host are found too; only the path is built.
+// Example:
+//
+//	app.GET("/users/:id", showUser).Name("user.show")
+//	path, err := app.URL("user.show", "id", "42") // "/users/42"
+func (s *Server) URL(name string, pairs ...string) (string, error) {
+	for _, h := range s.hosts {
+		if h.router.NamedRoute(name) != nil {
+			return h.router.URL(name, pairs...)
+		}
+	}
+	return s.router.URL(name, pairs...)
 }
 
 // ServeHTTP implements http.Handler, so Falcon Server can be passed
-// directly to http.ListenAndServe. It finds the route, applies conditional middleware,
-// executes the handler, and writes the Response as JSON.
+// directly to http.ListenAndServe. It finds the route (or the NotFound,
+// MethodNotAllowed or automatic OPTIONS fallback), runs its prebuilt
+// middleware chain, and writes the Response as JSON.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ch := s.compiled()
 	c := &server.Context{Writer: w, Request: r}
-
-	// Find the matching handler and path parameters
-	handler, params := s.router.FindHandler(r.Method, r.URL.Path)
-	if handler == nil {
-		http.NotFound(w, r)
-		return
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		c.Set(server.ClientCertKey, server.NewClientCert(r.TLS.VerifiedChains[0][0]))
 	}
-	c.Params = params
 
-	// Apply conditional middleware if the request path matches any pattern
-	final := handler
-	for _, cm := range s.conditionalMiddleware {
-		if strings.HasPrefix(r.URL.Path, strings.TrimSuffix(cm.Pattern, "*")) {
-			final = cm.Middleware(final)
+	// Find the matching route and path parameters, routing on the raw
+	// path so encoded slashes stay inside a parameter
+	p, raw := routingPath(r.URL)
+	var ps server.Params
+	rt, head := s.find(r.Method, r.Host, p, raw, false, &ps)
+
+	// Paths that only match once normalized are redirected or served on
+	// the canonical path, so conditional middleware and mounted handlers
+	// see the path the route was matched with
+	if rt == nil && s.routing != (RoutingConfig{}) {
+		var canonical string
+		var policy PathPolicy
+		if rt, head, canonical, policy = s.normalize(r.Method, r.Host, p, raw, &ps); policy == PathRedirect {
+			s.runRequestHooks(c)
+			code := redirectToCanonical(w, r, canonical, raw)
+			s.runResponseHooks(c, &server.Response{Success: true, Message: "Redirected to " + canonical, Code: code})
+			return
 		}
+		if rt != nil {
+			c.Request = withPath(r, canonical, raw)
+		}
+	}
+	if raw {
+		unescapeParams(ps)
+	}
+
+	// HEAD requests answered by a GET route have the body discarded
+	var hw *headResponseWriter
+	if head {
+		hw = &headResponseWriter{ResponseWriter: w}
+		c.Writer = hw
 	}
 
-	// Execute the handler
-	resp := final(c)
+	// Unmatched requests get a fallback handler
+	var handler server.HandlerFunc
+	if rt != nil {
+		handler = ch.routes[rt]
+	}
+	if handler == nil {
+		handler = s.fallback(c, ch)
+	}
+	c.Params = ps.Map()
+	s.runRequestHooks(c)
 
-	// Write JSON response
-	if !c.Handled && resp != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(resp.Code)
-		if err := json.NewEncoder(w).Encode(resp); err != nil {
-			log.Printf("failed to encode JSON response: %v", err)
-		}
+	// Execute the handler and write its response
+	resp := handler(c)
+	s.writeResponse(c, resp)
+	if hw != nil {
+		hw.flush()
 	}
+	s.runResponseHooks(c, resp)
+}
 
+// writeResponse encodes resp as JSON unless the handler already wrote
+// to the client itself.
+func (s *Server) writeResponse(c *server.Context, resp *server.Response) {
+	if c.Handled || resp == nil {
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.WriteHeader(resp.Code)
+	if err := json.NewEncoder(c.Writer).Encode(resp); err != nil {
+		log.Printf("failed to encode JSON response: %v", err)
+	}
 }
 
 // Start runs the HTTP server on the specified address. It logs the startup
-// and will terminate the program if ListenAndServe returns an error.
+// and terminates the program if the server fails; after a graceful
+// Shutdown (see ShutdownOnSignal) it returns. Use Run to get the error
+// instead.
 func (s *Server) Start(addr string) {
 	log.Printf("Starting server on %s", addr)
-	if err := http.ListenAndServe(addr, s); err != nil {
-		log.Fatal(err)
+	if err := s.Run(context.Background(), addr); err != nil {
+		logFatal(err)
 	}
 }
 
@@ -130,3 +282,13 @@ func (s *Server) Start(addr string) {
 func NewTemplateRenderer(pattern string, devMode bool, funcs template.FuncMap) *server.TemplateRenderer {
 	return server.NewTemplateRenderer(pattern, devMode, funcs)
 }
+
+// NewTemplateRenderer creates a TemplateRenderer whose built-in "url"
+// template func builds URLs for this server's named routes:
+//
+//	<a href="{{ url "user.show" "id" .ID }}">profile</a>
+func (s *Server) NewTemplateRenderer(pattern string, devMode bool, funcs template.FuncMap) *server.TemplateRenderer {
+	tr := server.NewTemplateRenderer(pattern, devMode, funcs)
+	tr.SetURLBuilder(s.URL)
+	return tr
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ascendingheavens/falcon/server"
@@ -93,6 +95,12 @@ func TestServer_HTTPMethods(t *testing.T) {
 		{"PUT", func(s *Server, p string, h server.HandlerFunc) { s.PUT(p, h) }, http.MethodPut, "/put"},
 		{"PATCH", func(s *Server, p string, h server.HandlerFunc) { s.PATCH(p, h) }, http.MethodPatch, "/patch"},
 		{"DELETE", func(s *Server, p string, h server.HandlerFunc) { s.DELETE(p, h) }, http.MethodDelete, "/delete"},
+		{"HEAD", func(s *Server, p string, h server.HandlerFunc) { s.HEAD(p, h) }, http.MethodHead, "/head"},
+		{"OPTIONS", func(s *Server, p string, h server.HandlerFunc) { s.OPTIONS(p, h) }, http.MethodOptions, "/options"},
+		{"Any", func(s *Server, p string, h server.HandlerFunc) { s.Any(p, h) }, http.MethodTrace, "/any"},
+		{"Match", func(s *Server, p string, h server.HandlerFunc) {
+			s.Match([]string{http.MethodGet, http.MethodPost}, p, h)
+		}, http.MethodPost, "/match"},
 	}
 
 	for _, tt := range methods {
@@ -122,3 +130,228 @@ func TestServer_HTTPMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_CatchAllParam(t *testing.T) {
+	s := New()
+	s.GET("/static/*filepath", func(c *server.Context) *server.Response {
+		return &server.Response{Success: true, Message: c.Param("filepath"), Code: 200}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/static/js/vendor/app.js", nil)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	assert.Equal(t, 200, rec.Code)
+	var resp server.Response
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "js/vendor/app.js", resp.Message)
+}
+
+func TestServer_StaticRouteHasWritableParams(t *testing.T) {
+	s := New()
+	s.GET("/health", func(c *server.Context) *server.Response {
+		c.Params["seen"] = "yes"
+		return c.String(http.StatusOK, c.Param("seen"))
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "yes", rec.Body.String())
+}
+
+func TestServer_ConstrainedParamRejectsWith404(t *testing.T) {
+	s := New()
+	handlerCalled := false
+	s.GET("/users/:id<int>", func(c *server.Context) *server.Response {
+		handlerCalled = true
+		return &server.Response{Success: true, Message: c.Param("id"), Code: 200}
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+	assert.Equal(t, 404, rec.Code)
+	assert.False(t, handlerCalled)
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/12", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.True(t, handlerCalled)
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	s := New()
+	ok := func(c *server.Context) *server.Response {
+		return &server.Response{Success: true, Message: "ok", Code: 200}
+	}
+	s.GET("/users/:id", ok)
+	s.DELETE("/users/:id", ok)
+	s.PUT("/users/:id<int>", ok)
+
+	t.Run("default handler", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users/7", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "DELETE, GET, HEAD, OPTIONS, PUT", rec.Header().Get("Allow"))
+
+		var resp server.Response
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.False(t, resp.Success)
+		assert.Equal(t, "Method Not Allowed", resp.Message)
+	})
+
+	t.Run("constraints are honoured in Allow", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users/bob", nil))
+		assert.Equal(t, "DELETE, GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+	})
+
+	t.Run("custom handler", func(t *testing.T) {
+		s.MethodNotAllowed(func(c *server.Context) *server.Response {
+			return c.String(http.StatusMethodNotAllowed, "nope: "+c.Writer.Header().Get("Allow"))
+		})
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/users/7", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "nope: DELETE, GET, HEAD, OPTIONS, PUT", rec.Body.String())
+	})
+
+	t.Run("unknown path is still 404", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/nothing", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get("Allow"))
+	})
+}
+
+func TestServer_AutomaticHEAD(t *testing.T) {
+	s := New()
+	s.GET("/hello", func(c *server.Context) *server.Response {
+		c.Writer.Header().Set("X-Custom", "yes")
+		return c.String(http.StatusOK, "hello world")
+	})
+	s.GET("/json", func(c *server.Context) *server.Response {
+		return &server.Response{Success: true, Message: "ok", Code: http.StatusAccepted}
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/hello", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "yes", rec.Header().Get("X-Custom"))
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "11", rec.Header().Get("Content-Length"))
+	assert.Zero(t, rec.Body.Len())
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/json", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rec.Header().Get("Content-Length"))
+	assert.Zero(t, rec.Body.Len())
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_AutomaticOPTIONS(t *testing.T) {
+	ok := func(c *server.Context) *server.Response {
+		return &server.Response{Success: true, Message: "ok", Code: 200}
+	}
+
+	t.Run("answers with Allow", func(t *testing.T) {
+		s := New()
+		s.GET("/users", ok)
+		s.POST("/users", ok)
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS, POST", rec.Header().Get("Allow"))
+		assert.Zero(t, rec.Body.Len())
+	})
+
+	t.Run("global middleware can take over", func(t *testing.T) {
+		s := New()
+		s.Use(func(next server.HandlerFunc) server.HandlerFunc {
+			return func(c *server.Context) *server.Response {
+				if c.Request.Method == http.MethodOptions {
+					c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+					return c.String(http.StatusOK, "preflight")
+				}
+				return next(c)
+			}
+		})
+		s.PUT("/users/:id", ok)
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users/1", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "preflight", rec.Body.String())
+	})
+
+	t.Run("unknown path is 404", func(t *testing.T) {
+		s := New()
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/nothing", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestServer_NamedRoutesAndURL(t *testing.T) {
+	s := New()
+	s.GET("/users/:id", func(c *server.Context) *server.Response {
+		return &server.Response{Success: true, Message: "ok", Code: 200}
+	}).Name("user.show")
+	s.Group("/api").POST("/items/:id", nil).Name("api.item")
+	s.GET("/go", func(c *server.Context) *server.Response {
+		target, err := s.URL("user.show", "id", "42")
+		assert.NoError(t, err)
+		return c.Redirect(http.StatusFound, target)
+	})
+
+	path, err := s.URL("api.item", "id", "9")
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/items/9", path)
+
+	_, err = s.URL("user.show", "name", "x")
+	assert.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/go", nil))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/users/42", rec.Header().Get("Location"))
+}
+
+func TestServer_NewTemplateRendererWiresURL(t *testing.T) {
+	tmpl := filepath.Join(t.TempDir(), "link.html")
+	assert.NoError(t, os.WriteFile(tmpl, []byte(`{{ url "user.show" "id" . }}`), 0644))
+
+	s := New()
+	s.GET("/users/:id", nil).Name("user.show")
+	tr := s.NewTemplateRenderer(tmpl, false, nil)
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, tr.Render(rec, "link.html", "5"))
+	assert.Equal(t, "/users/5", rec.Body.String())
+}
+
+func TestServer_RouteConflicts(t *testing.T) {
+	s := New()
+	ok := func(c *server.Context) *server.Response { return nil }
+	s.GET("/users/:id", ok)
+
+	_, err := s.TryHandle(http.MethodGet, "/users/:id", ok)
+	assert.ErrorIs(t, err, server.ErrRouteConflict)
+
+	_, err = s.Group("/users").TryHandle(http.MethodGet, "/:name", ok)
+	assert.ErrorIs(t, err, server.ErrRouteConflict)
+
+	assert.Panics(t, func() { s.POST("/x/:", ok) })
+	assert.Panics(t, func() { s.Group("/users").GET("/:id", ok) })
+
+	// Any conflicts with the GET that is already registered
+	assert.Panics(t, func() { s.Any("/users/:id", ok) })
+}
@@ -0,0 +1,148 @@
+package falcon
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// NotFound sets the handler used when no route matches the request path.
+// It runs through the global and conditional middleware like any route.
+// By default a JSON 404 Response is returned.
+// Example:
+//
+//	app.NotFound(func(c *falcon.Context) *falcon.Response {
+//		return c.HTML(404, "<h1>Nothing here</h1>")
+//	})
+func (s *Server) NotFound(handler server.HandlerFunc) {
+	s.notFound = handler
+	s.invalidate()
+}
+
+// MethodNotAllowed sets the handler used when a request path matches a
+// registered route but its method does not. The Allow header listing every
+// registered method for the path is already set when the handler runs.
+// It runs through the global and conditional middleware like any route.
+// By default a JSON 405 Response is returned.
+// Example:
+//
+//	app.MethodNotAllowed(func(c *falcon.Context) *falcon.Response {
+//		return c.ErrorJSON("use one of: "+c.Writer.Header().Get("Allow"), nil, 405)
+//	})
+func (s *Server) MethodNotAllowed(handler server.HandlerFunc) {
+	s.methodNotAllowed = handler
+	s.invalidate()
+}
+
+// NotFound sets the handler used for unmatched paths under the group prefix.
+// It overrides the server handler and also runs through the group middleware.
+func (g *Group) NotFound(handler HandlerFunc) {
+	g.notFound = handler
+	g.Server.invalidate()
+}
+
+// MethodNotAllowed sets the 405 handler for paths under the group prefix.
+// It overrides the server handler and also runs through the group middleware.
+func (g *Group) MethodNotAllowed(handler HandlerFunc) {
+	g.methodNotAllowed = handler
+	g.Server.invalidate()
+}
+
+// fallback returns the handler for a request that matched no route:
+// automatic OPTIONS or MethodNotAllowed when the path is registered under
+// other methods (both with an Allow header), NotFound otherwise.
+// Group overrides and the automatic OPTIONS of groups win over the server
+// handlers, the most specific group prefix first and host groups ahead of others with the same prefix. All
+// of them come from ch, already wrapped in middleware.
+func (s *Server) fallback(c *server.Context, ch *chains) server.HandlerFunc {
+	path, raw := routingPath(c.Request.URL)
+
+	handler, overrides := ch.notFound, ch.groupNotFound
+	if allowed := s.allowedMethods(c.Request.Host, path, raw); len(allowed) > 0 {
+		c.Writer.Header().Set("Allow", strings.Join(allowed, ", "))
+		handler, overrides = ch.methodNotAllowed, ch.groupMethodNotAllowed
+		if c.Request.Method == http.MethodOptions {
+			handler, overrides = ch.options, ch.groupOptions
+		}
+	}
+
+	var owner *Group
+	var ps server.Params
+	for _, g := range s.groups {
+		h, ok := overrides[g]
+		if !ok || !hasPathPrefix(path, g.Prefix) {
+			continue
+		}
+		if g.host != nil && !g.host.match(hostname(c.Request.Host), &ps) {
+			continue
+		}
+		if owner == nil || len(g.Prefix) > len(owner.Prefix) || (len(g.Prefix) == len(owner.Prefix) && owner.host == nil) {
+			handler, owner = h, g
+		}
+	}
+	return handler
+}
+
+// allowedMethods returns every method a request for host and path may
+// use: the registered ones plus HEAD for GET routes and OPTIONS, which are
+// answered automatically. Paths are normalized as configured by
+// RoutingConfig, like for the route lookup. It returns nil if no route
+// matches path.
+func (s *Server) allowedMethods(host, path string, raw bool) []string {
+	allowed := s.routerMethods(host, path, raw, false)
+	if len(allowed) == 0 && s.routing != (RoutingConfig{}) {
+		s.normalized(path, func(p string, fold bool) bool {
+			allowed = s.routerMethods(host, p, raw, fold)
+			return len(allowed) > 0
+		})
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	slices.Sort(allowed)
+	return allowed
+}
+
+// hasPathPrefix reports whether path is prefix itself or lies below it,
+// so "/api" covers "/api" and "/api/users" but not "/apiary".
+func hasPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+// automaticOptions answers OPTIONS requests for paths without an explicit
+// OPTIONS route. The Allow header has already been set by fallback.
+func automaticOptions(c *server.Context) *server.Response {
+	c.Writer.WriteHeader(http.StatusNoContent)
+	c.Handled = true
+	return &server.Response{Success: true, Message: "Allow: " + c.Writer.Header().Get("Allow"), Code: http.StatusNoContent}
+}
+
+// defaultNotFound answers with a JSON 404 Response.
+func defaultNotFound(c *server.Context) *server.Response {
+	return &server.Response{
+		Success: false,
+		Message: http.StatusText(http.StatusNotFound),
+		Code:    http.StatusNotFound,
+	}
+}
+
+// defaultMethodNotAllowed answers with a JSON 405 Response.
+func defaultMethodNotAllowed(c *server.Context) *server.Response {
+	return &server.Response{
+		Success: false,
+		Message: http.StatusText(http.StatusMethodNotAllowed),
+		Code:    http.StatusMethodNotAllowed,
+	}
+}
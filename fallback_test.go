@@ -0,0 +1,158 @@
+package falcon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ascendingheavens/falcon/middleware"
+	"github.com/ascendingheavens/falcon/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func headerMiddleware(key string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) *Response {
+			c.Writer.Header().Set(key, "1")
+			return next(c)
+		}
+	}
+}
+
+func TestServer_DefaultNotFoundIsJSON(t *testing.T) {
+	s := New()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var resp server.Response
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.False(t, resp.Success)
+	assert.Equal(t, "Not Found", resp.Message)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestServer_NotFoundRunsThroughMiddleware(t *testing.T) {
+	s := New()
+	var seen *Response
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) *Response {
+			seen = next(c)
+			return seen
+		}
+	})
+	s.UseIf("/api/*", headerMiddleware("X-Conditional"))
+	s.NotFound(func(c *Context) *Response {
+		return c.String(http.StatusNotFound, "custom not found")
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/missing", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "custom not found", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("X-Conditional"))
+	if assert.NotNil(t, seen) {
+		assert.Equal(t, http.StatusNotFound, seen.Code)
+	}
+}
+
+func TestServer_MethodNotAllowedRunsThroughMiddleware(t *testing.T) {
+	s := New()
+	s.Use(headerMiddleware("X-Global"))
+	s.GET("/users", func(c *Context) *Response { return nil })
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-Global"))
+	assert.Equal(t, "GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+}
+
+func TestGroup_FallbackOverrides(t *testing.T) {
+	s := New()
+	s.Use(headerMiddleware("X-Global"))
+	s.NotFound(func(c *Context) *Response { return c.String(http.StatusNotFound, "server") })
+
+	api := s.Group("/api")
+	api.Use(headerMiddleware("X-API"))
+	api.NotFound(func(c *Context) *Response { return c.String(http.StatusNotFound, "api") })
+	api.MethodNotAllowed(func(c *Context) *Response { return c.String(http.StatusMethodNotAllowed, "api 405") })
+	api.GET("/users", func(c *Context) *Response { return nil })
+
+	admin := s.Group("/api/admin")
+	admin.NotFound(func(c *Context) *Response { return c.String(http.StatusNotFound, "admin") })
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+		apiMW        bool
+	}{
+		{http.MethodGet, "/api/missing", http.StatusNotFound, "api", true},
+		{http.MethodGet, "/api", http.StatusNotFound, "api", true},
+		{http.MethodGet, "/api/admin/missing", http.StatusNotFound, "admin", false},
+		{http.MethodGet, "/apiary", http.StatusNotFound, "server", false},
+		{http.MethodPost, "/api/users", http.StatusMethodNotAllowed, "api 405", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Equal(t, tt.body, rec.Body.String())
+			assert.Equal(t, "1", rec.Header().Get("X-Global"))
+			if tt.apiMW {
+				assert.Equal(t, "1", rec.Header().Get("X-API"))
+			} else {
+				assert.Empty(t, rec.Header().Get("X-API"))
+			}
+		})
+	}
+}
+
+func TestGroup_AutomaticOPTIONSRunsThroughGroupMiddleware(t *testing.T) {
+	s := New()
+	s.GET("/public", func(c *Context) *Response { return nil })
+	api := s.Group("/api")
+	api.Use(middleware.CORS())
+	api.GET("/users", func(c *Context) *Response { return nil })
+	admin := api.Group("/admin")
+	admin.GET("/stats", func(c *Context) *Response { return nil })
+
+	preflight := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("/api/users")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.NotEmpty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+
+	rec = preflight("/api/admin/stats")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"), "nested groups inherit the middleware")
+
+	rec = preflight("/public")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	assert.True(t, hasPathPrefix("/api", "/api"))
+	assert.True(t, hasPathPrefix("/api/users", "/api"))
+	assert.True(t, hasPathPrefix("/api/users", "/api/"))
+	assert.True(t, hasPathPrefix("/anything", ""))
+	assert.False(t, hasPathPrefix("/apiary", "/api"))
+	assert.False(t, hasPathPrefix("/ap", "/api"))
+}
@@ -2,6 +2,7 @@ package falcon
 
 import (
 	"net/http"
+	"slices"
 
 	"github.com/ascendingheavens/falcon/middleware"
 )
@@ -10,67 +11,141 @@ import (
 // and middleware stack. Useful for organizing related endpoints.
 // Example: v1 := app.Group("/api/v1")
 func (s *Server) Group(prefix string) *Group {
-	return &Group{
+	g := &Group{
 		Prefix:      prefix,
 		Server:      s,
 		Middlewares: make([]middleware.Middleware, 0),
 	}
+	s.groups = append(s.groups, g)
+	return g
 }
 
-// Use registers a middleware for this specific group.
-// These middlewares are applied only to routes within the group,
-// in addition to any global middleware from the parent server.
-func (g *Group) Use(mw middleware.Middleware) {
-	g.Middlewares = append(g.Middlewares, mw)
+// Route creates a group for prefix and passes it to fn, which keeps the
+// routes of a section together.
+// Example:
+//
+//	app.Route("/api", func(api *falcon.Group) {
+//		api.Use(AuthMiddleware())
+//		api.GET("/users", listUsers)
+//	})
+func (s *Server) Route(prefix string, fn func(g *Group)) *Group {
+	g := s.Group(prefix)
+	fn(g)
+	return g
 }
 
-// Handle registers a route for the group with a specific HTTP method and path.
-// It automatically prepends the group's prefix to the path and applies
-// the group's middleware stack in reverse order for correct execution.
-func (g *Group) Handle(method, path string, handler HandlerFunc) {
-	fullPath := g.Prefix + path
+// Group creates a child group whose prefix is appended to this group's
+// prefix. The child inherits the middleware of this group and of every
+// ancestor, including middleware added to them later.
+// Example: v1 := app.Group("/api").Group("/v1") // "/api/v1"
+func (g *Group) Group(prefix string) *Group {
+	child := g.Server.Group(g.Prefix + prefix)
+	child.parent = g
+	child.host = g.host
+	return child
+}
 
-	combined := handler
+// Route creates a child group for prefix and passes it to fn.
+// Example:
+//
+//	api.Route("/v1", func(v1 *falcon.Group) {
+//		v1.GET("/users", listUsers)
+//	})
+func (g *Group) Route(prefix string, fn func(g *Group)) *Group {
+	child := g.Group(prefix)
+	fn(child)
+	return child
+}
 
-	// Apply server-level middlewares first
-	for i := len(g.Server.middlewares) - 1; i >= 0; i-- {
-		combined = g.Server.middlewares[i](combined)
+// chain returns the middleware of this group and all its ancestors,
+// ordered from the outermost group to this one.
+func (g *Group) chain() []middleware.Middleware {
+	if g.parent == nil {
+		return g.Middlewares
 	}
+	return append(slices.Clip(g.parent.chain()), g.Middlewares...)
+}
+
+// Use registers a middleware for this specific group.
+// These middlewares are applied only to routes within the group and its
+// child groups, inside any global and conditional middleware of the server.
+// Like Server.Use, it also covers routes registered before the call.
+func (g *Group) Use(mw middleware.Middleware) {
+	g.Middlewares = append(g.Middlewares, mw)
+	g.Server.invalidate()
+}
 
-	// Then group-specific middlewares
-	for i := len(g.Middlewares) - 1; i >= 0; i-- {
-		combined = g.Middlewares[i](combined)
+// Handle registers a route for the group with a specific HTTP method and path.
+// It automatically prepends the group's prefix to the path. When serving,
+// the route runs inside the global and conditional middleware, then the
+// middleware of each enclosing group from the outermost to this one.
+// Like Server.Handle, it panics on malformed or conflicting routes.
+func (g *Group) Handle(method, path string, handler HandlerFunc) *Route {
+	rt, err := g.TryHandle(method, path, handler)
+	if err != nil {
+		panic("falcon: " + err.Error())
 	}
+	return rt
+}
 
-	g.Server.router.Handle(method, fullPath, combined)
+// TryHandle registers a route for the group like Handle but returns an
+// error instead of panicking on malformed or conflicting routes.
+func (g *Group) TryHandle(method, path string, handler HandlerFunc) (*Route, error) {
+	return g.Server.register(method, g.Prefix+path, handler, g)
 }
 
 // GET registers a route with the HTTP GET method for this group.
 // The handler is invoked when a request matches the given path.
-func (g *Group) GET(path string, handler HandlerFunc) {
-	g.Handle(http.MethodGet, path, handler)
+func (g *Group) GET(path string, handler HandlerFunc) *Route {
+	return g.Handle(http.MethodGet, path, handler)
 }
 
 // POST registers a route with the HTTP POST method for this group.
 // The handler is invoked when a request matches the given path.
-func (g *Group) POST(path string, handler HandlerFunc) {
-	g.Handle(http.MethodPost, path, handler)
+func (g *Group) POST(path string, handler HandlerFunc) *Route {
+	return g.Handle(http.MethodPost, path, handler)
 }
 
 // PUT registers a route with the HTTP PUT method for this group.
 // The handler is invoked when a request matches the given path.
-func (g *Group) PUT(path string, handler HandlerFunc) {
-	g.Handle(http.MethodPut, path, handler)
+func (g *Group) PUT(path string, handler HandlerFunc) *Route {
+	return g.Handle(http.MethodPut, path, handler)
 }
 
 // PATCH registers a route with the HTTP PATCH method for this group.
 // The handler is invoked when a request matches the given path.
-func (g *Group) PATCH(path string, handler HandlerFunc) {
-	g.Handle(http.MethodPatch, path, handler)
+func (g *Group) PATCH(path string, handler HandlerFunc) *Route {
+	return g.Handle(http.MethodPatch, path, handler)
 }
 
 // DELETE registers a route with the HTTP DELETE method for this group.
 // The handler is invoked when a request matches the given path.
-func (g *Group) DELETE(path string, handler HandlerFunc) {
-	g.Handle(http.MethodDelete, path, handler)
+func (g *Group) DELETE(path string, handler HandlerFunc) *Route {
+	return g.Handle(http.MethodDelete, path, handler)
+}
+
+// HEAD registers a route with the HTTP HEAD method for this group.
+// Without it, HEAD requests are answered by the matching GET route.
+func (g *Group) HEAD(path string, handler HandlerFunc) *Route {
+	return g.Handle(http.MethodHead, path, handler)
+}
+
+// OPTIONS registers a route with the HTTP OPTIONS method for this group.
+// Without it, OPTIONS requests are answered with 204 and an Allow header.
+func (g *Group) OPTIONS(path string, handler HandlerFunc) *Route {
+	return g.Handle(http.MethodOptions, path, handler)
+}
+
+// Any registers the handler for every standard HTTP method for this group.
+func (g *Group) Any(path string, handler HandlerFunc) []*Route {
+	return g.Match(anyMethods, path, handler)
+}
+
+// Match registers the handler for each of the given HTTP methods for this group.
+func (g *Group) Match(methods []string, path string, handler HandlerFunc) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	for _, m := range methods {
+		routes = append(routes, g.Handle(m, path, handler))
+	}
+	return routes
 }
@@ -49,11 +49,12 @@ func TestGroup_Handle(t *testing.T) {
 
 	// Simulate a request to check middleware execution
 	rec := httptest.NewRecorder()
-	c := &Context{Writer: rec}
-	resp := h(c)
+	testServer.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/test", nil))
 	assert.True(t, handlerCalled)
 	assert.Equal(t, "1", rec.Header().Get("X-MW1")) // server-level middleware
 	assert.Equal(t, "1", rec.Header().Get("X-MW2")) // group-level middleware
+	var resp Response
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
 	assert.Equal(t, 200, resp.Code)
 	assert.Equal(t, "ok", resp.Message)
 }
@@ -70,6 +71,10 @@ func TestGroup_HTTPMethods(t *testing.T) {
 		{"PUT", func(g *Group, p string, h server.HandlerFunc) { g.PUT(p, h) }, http.MethodPut, "/put"},
 		{"PATCH", func(g *Group, p string, h server.HandlerFunc) { g.PATCH(p, h) }, http.MethodPatch, "/patch"},
 		{"DELETE", func(g *Group, p string, h server.HandlerFunc) { g.DELETE(p, h) }, http.MethodDelete, "/delete"},
+		{"HEAD", func(g *Group, p string, h server.HandlerFunc) { g.HEAD(p, h) }, http.MethodHead, "/head"},
+		{"OPTIONS", func(g *Group, p string, h server.HandlerFunc) { g.OPTIONS(p, h) }, http.MethodOptions, "/options"},
+		{"Any", func(g *Group, p string, h server.HandlerFunc) { g.Any(p, h) }, http.MethodPatch, "/any"},
+		{"Match", func(g *Group, p string, h server.HandlerFunc) { g.Match([]string{http.MethodPut}, p, h) }, http.MethodPut, "/match"},
 	}
 
 	for _, tt := range methods {
@@ -101,3 +106,83 @@ func TestGroup_HTTPMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestGroup_ImplementsHandler(t *testing.T) {
+	var _ Handler = New()
+	var _ Handler = New().Group("/api")
+}
+
+func TestGroup_Nested(t *testing.T) {
+	s := New()
+	var order []string
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) *Response {
+				order = append(order, name)
+				return next(c)
+			}
+		}
+	}
+
+	api := s.Group("/api")
+	api.Use(record("api"))
+	v1 := api.Group("/v1")
+	v1.Use(record("v1"))
+	admin := v1.Group("/admin")
+	admin.Use(record("admin"))
+	// Middleware added to a parent after the child was created still applies
+	api.Use(record("api-late"))
+
+	admin.GET("/users/:id", func(c *Context) *Response {
+		order = append(order, "handler")
+		return c.String(http.StatusOK, c.Param("id"))
+	})
+
+	assert.Equal(t, "/api/v1/admin", admin.Prefix)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/users/9", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "9", rec.Body.String())
+	assert.Equal(t, []string{"api", "api-late", "v1", "admin", "handler"}, order)
+
+	// Siblings do not share middleware
+	order = nil
+	api.Group("/v2").GET("/ping", func(c *Context) *Response { return c.String(http.StatusOK, "pong") })
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/ping", nil))
+	assert.Equal(t, []string{"api", "api-late"}, order)
+}
+
+func TestGroup_RouteCallback(t *testing.T) {
+	s := New()
+	var inner *Group
+	outer := s.Route("/api", func(api *Group) {
+		api.Use(headerMiddleware("X-API"))
+		inner = api.Route("/v1", func(v1 *Group) {
+			v1.GET("/users", func(c *Context) *Response { return c.String(http.StatusOK, "users") })
+		})
+	})
+
+	assert.Equal(t, "/api", outer.Prefix)
+	assert.Equal(t, "/api/v1", inner.Prefix)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+	assert.Equal(t, "users", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("X-API"))
+	assert.Equal(t, 1, s.Routes()[0].Middlewares)
+}
+
+func TestGroup_NestedFallbackUsesAncestorMiddleware(t *testing.T) {
+	s := New()
+	api := s.Group("/api")
+	api.Use(headerMiddleware("X-API"))
+	v1 := api.Group("/v1")
+	v1.NotFound(func(c *Context) *Response { return c.String(http.StatusNotFound, "v1 missing") })
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/nothing", nil))
+	assert.Equal(t, "v1 missing", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("X-API"))
+}
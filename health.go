@@ -0,0 +1,194 @@
+package falcon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultHealthTimeout bounds a health check unless
+// HealthCheckConfig.Timeout is set.
+const DefaultHealthTimeout = 5 * time.Second
+
+// HealthCheckConfig defines how a health check is run.
+type HealthCheckConfig struct {
+	// Timeout bounds a single run of the check. Defaults to
+	// DefaultHealthTimeout.
+	Timeout time.Duration
+
+	// CacheFor reuses the last result for this long, so frequent probes
+	// do not hammer the checked dependency. Zero runs the check on every
+	// probe.
+	CacheFor time.Duration
+
+	// Liveness runs the check on the liveness endpoint as well as on the
+	// readiness endpoint. Only use it for failures a restart fixes.
+	Liveness bool
+}
+
+// HealthReport is the Details of a health endpoint Response.
+type HealthReport struct {
+	Status string                       `json:"status"` // "up" or "down"
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the outcome of one health check.
+type HealthCheckResult struct {
+	Status    string    `json:"status"` // "up" or "down"
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Health serves liveness and readiness endpoints backed by named checks.
+// Create it with Server.Health.
+type Health struct {
+	server *Server
+	mu     sync.RWMutex
+	checks []*healthCheck
+}
+
+// healthCheck is a registered check with its cached result.
+type healthCheck struct {
+	name string
+	fn   func(ctx context.Context) error
+	cfg  HealthCheckConfig
+
+	mu     sync.Mutex // held while the check runs, so probes share a run
+	result HealthCheckResult
+	expiry time.Time
+}
+
+// Health registers GET handlers for a liveness endpoint at livePath and a
+// readiness endpoint at readyPath; an empty path is not registered. Both
+// answer 200 when their checks pass and 503 otherwise, with a
+// HealthReport in the Response. Readiness runs every check and fails as
+// soon as Shutdown begins, so load balancers stop sending traffic (see
+// Config.ShutdownDelay); liveness only runs checks marked Liveness.
+// Example:
+//
+//	health := app.Health("/healthz", "/readyz")
+//	health.CheckWithConfig("db", db.PingContext, falcon.HealthCheckConfig{
+//		Timeout:  time.Second,
+//		CacheFor: 5 * time.Second,
+//	})
+func (s *Server) Health(livePath, readyPath string) *Health {
+	h := &Health{server: s}
+	if livePath != "" {
+		s.GET(livePath, func(c *Context) *Response { return h.respond(c, false) })
+	}
+	if readyPath != "" {
+		s.GET(readyPath, func(c *Context) *Response { return h.respond(c, true) })
+	}
+	return h
+}
+
+// Check registers a readiness check named name with the default
+// configuration. It panics if the name is already taken or fn is nil.
+func (h *Health) Check(name string, fn func(ctx context.Context) error) {
+	h.CheckWithConfig(name, fn, HealthCheckConfig{})
+}
+
+// CheckWithConfig registers a check named name using cfg. It panics if
+// the name is already taken or fn is nil.
+func (h *Health) CheckWithConfig(name string, fn func(ctx context.Context) error, cfg HealthCheckConfig) {
+	if fn == nil {
+		panic("falcon: health check " + name + " has a nil function")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultHealthTimeout
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, hc := range h.checks {
+		if hc.name == name {
+			panic("falcon: health check " + name + " already registered")
+		}
+	}
+	h.checks = append(h.checks, &healthCheck{name: name, fn: fn, cfg: cfg})
+}
+
+// respond runs the checks for the liveness or readiness endpoint
+// concurrently and reports them.
+func (h *Health) respond(c *Context, ready bool) *Response {
+	h.mu.RLock()
+	var checks []*healthCheck
+	for _, hc := range h.checks {
+		if ready || hc.cfg.Liveness {
+			checks = append(checks, hc)
+		}
+	}
+	h.mu.RUnlock()
+
+	report := HealthReport{Status: "up"}
+	if len(checks) > 0 {
+		report.Checks = make(map[string]HealthCheckResult, len(checks))
+		results := make([]HealthCheckResult, len(checks))
+		var wg sync.WaitGroup
+		for i, hc := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = hc.run(c.Request.Context())
+			}()
+		}
+		wg.Wait()
+		for i, hc := range checks {
+			report.Checks[hc.name] = results[i]
+			if results[i].Status != "up" {
+				report.Status = "down"
+			}
+		}
+	}
+
+	code, message := http.StatusOK, "Healthy"
+	switch {
+	case ready && h.server.shuttingDown():
+		report.Status, code, message = "down", http.StatusServiceUnavailable, "Shutting down"
+	case report.Status != "up":
+		code, message = http.StatusServiceUnavailable, "Unhealthy"
+	}
+	return &Response{Success: code == http.StatusOK, Message: message, Details: report, Code: code}
+}
+
+// run returns the cached result while it is fresh and runs the check
+// otherwise. A check that does not return within its timeout is reported
+// as down even if it ignores ctx, and so is one that panics. The result
+// is shared by later probes, so
+// the check keeps running if the probe that started it goes away.
+func (hc *healthCheck) run(ctx context.Context) HealthCheckResult {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	now := time.Now()
+	if now.Before(hc.expiry) {
+		return hc.result
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), hc.cfg.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- hc.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", hc.cfg.Timeout)
+	}
+
+	result := HealthCheckResult{Status: "up", Duration: time.Since(now).String(), CheckedAt: now}
+	if err != nil {
+		result.Status, result.Error = "down", err.Error()
+	}
+	hc.result, hc.expiry = result, now.Add(hc.cfg.CacheFor)
+	return result
+}
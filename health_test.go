@@ -0,0 +1,167 @@
+package falcon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type healthBody struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Details HealthReport `json:"details"`
+	Code    int          `json:"code"`
+}
+
+func probe(t *testing.T, s *Server, path string) healthBody {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body healthBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	assert.Equal(t, body.Code, rec.Code)
+	return body
+}
+
+func TestServer_Health(t *testing.T) {
+	var dbErr atomic.Value
+	dbErr.Store(errors.New(""))
+	s := New()
+	health := s.Health("/healthz", "/readyz")
+	health.Check("db", func(ctx context.Context) error {
+		if err := dbErr.Load().(error); err.Error() != "" {
+			return err
+		}
+		return nil
+	})
+	health.CheckWithConfig("deadlock", func(context.Context) error { return nil }, HealthCheckConfig{Liveness: true})
+
+	body := probe(t, s, "/readyz")
+	assert.True(t, body.Success)
+	assert.Equal(t, http.StatusOK, body.Code)
+	assert.Equal(t, "up", body.Details.Status)
+	assert.Equal(t, "up", body.Details.Checks["db"].Status)
+	assert.Equal(t, "up", body.Details.Checks["deadlock"].Status)
+	assert.False(t, body.Details.Checks["db"].CheckedAt.IsZero())
+
+	dbErr.Store(errors.New("connection refused"))
+	body = probe(t, s, "/readyz")
+	assert.False(t, body.Success)
+	assert.Equal(t, http.StatusServiceUnavailable, body.Code)
+	assert.Equal(t, "Unhealthy", body.Message)
+	assert.Equal(t, "down", body.Details.Checks["db"].Status)
+	assert.Equal(t, "connection refused", body.Details.Checks["db"].Error)
+
+	// Liveness only runs liveness checks
+	body = probe(t, s, "/healthz")
+	assert.Equal(t, http.StatusOK, body.Code)
+	assert.Contains(t, body.Details.Checks, "deadlock")
+	assert.NotContains(t, body.Details.Checks, "db")
+
+	assert.Panics(t, func() { health.Check("db", func(context.Context) error { return nil }) })
+	assert.Panics(t, func() { health.Check("cache", nil) })
+}
+
+func TestHealth_PanickingCheckIsDown(t *testing.T) {
+	s := New()
+	health := s.Health("", "/readyz")
+	health.Check("broken", func(context.Context) error { panic("nil pointer") })
+	health.Check("db", func(context.Context) error { return nil })
+
+	body := probe(t, s, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, body.Code)
+	assert.Equal(t, "down", body.Details.Checks["broken"].Status)
+	assert.Equal(t, "panic: nil pointer", body.Details.Checks["broken"].Error)
+	assert.Equal(t, "up", body.Details.Checks["db"].Status)
+}
+
+func TestHealth_CheckTimeoutAndCache(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+
+	s := New()
+	health := s.Health("", "/readyz")
+	health.CheckWithConfig("slow", func(ctx context.Context) error {
+		<-release // ignores ctx
+		return nil
+	}, HealthCheckConfig{Timeout: 20 * time.Millisecond})
+	health.CheckWithConfig("cached", func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}, HealthCheckConfig{CacheFor: time.Hour})
+
+	begin := time.Now()
+	body := probe(t, s, "/readyz")
+	assert.Less(t, time.Since(begin), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, body.Code)
+	assert.Equal(t, "timed out after 20ms", body.Details.Checks["slow"].Error)
+
+	probe(t, s, "/readyz")
+	probe(t, s, "/readyz")
+	assert.Equal(t, int32(1), runs.Load())
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "empty path is not registered")
+}
+
+func TestHealth_CachedResultIgnoresProbeCancellation(t *testing.T) {
+	s := New()
+	health := s.Health("", "/readyz")
+	health.CheckWithConfig("db", func(ctx context.Context) error {
+		select {
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, HealthCheckConfig{CacheFor: time.Hour})
+
+	// The client that triggered the check hangs up mid-probe
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	body := probe(t, s, "/readyz")
+	assert.Equal(t, http.StatusOK, body.Code)
+	assert.Empty(t, body.Details.Checks["db"].Error)
+}
+
+func TestHealth_ReadinessFailsDuringShutdown(t *testing.T) {
+	s := New()
+	s.Configure(Config{ShutdownDelay: 200 * time.Millisecond})
+	s.Health("/healthz", "/readyz")
+
+	addr, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	// Idle keep-alive connections would delay the graceful shutdown
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	get := func(path string) int {
+		res, err := client.Get("http://" + addr + path)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, get("/readyz"))
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- s.Shutdown(context.Background()) }()
+
+	// Still listening during the delay, but no longer ready
+	assert.Eventually(t, func() bool { return get("/readyz") == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+
+	assert.NoError(t, <-shutdownErr)
+	assert.NoError(t, stop())
+}
@@ -0,0 +1,158 @@
+package falcon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// hooks holds the functions registered with OnStart, OnShutdown,
+// OnRequest, OnResponse and OnError, each run in registration order.
+type hooks struct {
+	start    []func(ctx context.Context) error
+	shutdown []func(ctx context.Context) error
+	request  []func(c *Context)
+	response []func(c *Context, resp *Response)
+	error    []func(c *Context, resp *Response)
+}
+
+// OnStart registers a hook that runs once before the server starts
+// listening, e.g. to open database pools or warm caches. It gets the
+// context passed to Run (context.Background for Start and Serve). Hooks
+// run in registration order; the first error stops the remaining hooks
+// and aborts startup, so Run returns it and Start terminates the program.
+// Example:
+//
+//	app.OnStart(func(ctx context.Context) error {
+//		return db.PingContext(ctx)
+//	})
+func (s *Server) OnStart(fn func(ctx context.Context) error) {
+	s.hooks.start = append(s.hooks.start, fn)
+}
+
+// OnShutdown registers a hook that runs during Shutdown once in-flight
+// requests have finished, e.g. to flush telemetry or close database
+// pools. ctx carries the shutdown deadline. Hooks run in registration
+// order; all of them run and their errors are returned by Shutdown. They
+// are skipped if the server was never started or an OnStart hook failed,
+// as nothing was set up that needs tearing down.
+// Example:
+//
+//	app.OnShutdown(func(ctx context.Context) error {
+//		return tracerProvider.Shutdown(ctx)
+//	})
+func (s *Server) OnShutdown(fn func(ctx context.Context) error) {
+	s.hooks.shutdown = append(s.hooks.shutdown, fn)
+}
+
+// OnRequest registers a hook that runs for every request once it has
+// been routed, before the middleware and handler. c.Params holds the path
+// parameters of the matched route and is empty for unmatched requests.
+func (s *Server) OnRequest(fn func(c *Context)) {
+	s.hooks.request = append(s.hooks.request, fn)
+}
+
+// OnResponse registers a hook that runs for every request after the
+// response has been written, with the final Response returned through
+// the middleware, including the NotFound and MethodNotAllowed responses
+// of unmatched requests and routing redirects. A handler that returned
+// nil is reported as a 200 response.
+// Example:
+//
+//	app.OnResponse(func(c *falcon.Context, resp *falcon.Response) {
+//		metrics.Observe(c.Request.Method, resp.Code)
+//	})
+func (s *Server) OnResponse(fn func(c *Context, resp *Response)) {
+	s.hooks.response = append(s.hooks.response, fn)
+}
+
+// OnError registers a hook that runs like OnResponse, but only for
+// responses with a status code of 400 or above.
+func (s *Server) OnError(fn func(c *Context, resp *Response)) {
+	s.hooks.error = append(s.hooks.error, fn)
+}
+
+// runRequestHooks runs the OnRequest hooks.
+func (s *Server) runRequestHooks(c *Context) {
+	for _, fn := range s.hooks.request {
+		fn(c)
+	}
+}
+
+// runResponseHooks runs the OnResponse hooks, then the OnError hooks for
+// error responses.
+func (s *Server) runResponseHooks(c *Context, resp *Response) {
+	if len(s.hooks.response) == 0 && len(s.hooks.error) == 0 {
+		return
+	}
+	if resp == nil {
+		resp = &Response{Success: true, Message: http.StatusText(http.StatusOK), Code: http.StatusOK}
+	}
+	for _, fn := range s.hooks.response {
+		fn(c, resp)
+	}
+	if resp.Code >= http.StatusBadRequest {
+		for _, fn := range s.hooks.error {
+			fn(c, resp)
+		}
+	}
+}
+
+// start runs the OnStart hooks the first time it is called. Concurrent
+// and later calls wait for that run and return its result.
+func (s *Server) start(ctx context.Context) error {
+	s.life.mu.Lock()
+	if s.life.started != nil {
+		started := s.life.started
+		s.life.mu.Unlock()
+		<-started
+		s.life.mu.Lock()
+		defer s.life.mu.Unlock()
+		return s.life.startErr
+	}
+	started := make(chan struct{})
+	s.life.started = started
+	s.life.mu.Unlock()
+
+	var err error
+	for _, fn := range s.hooks.start {
+		if err = fn(ctx); err != nil {
+			break
+		}
+	}
+
+	s.life.mu.Lock()
+	s.life.startErr = err
+	s.life.mu.Unlock()
+	close(started)
+	return err
+}
+
+// runShutdownHooks runs every OnShutdown hook and joins their errors,
+// unless the start hooks never ran or failed. A start still in progress
+// is waited for.
+func (s *Server) runShutdownHooks(ctx context.Context) error {
+	s.life.mu.Lock()
+	started := s.life.started
+	s.life.mu.Unlock()
+	if started == nil {
+		return nil
+	}
+	select {
+	case <-started:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.life.mu.Lock()
+	startErr := s.life.startErr
+	s.life.mu.Unlock()
+	if startErr != nil {
+		return nil
+	}
+
+	var errs []error
+	for _, fn := range s.hooks.shutdown {
+		errs = append(errs, fn(ctx))
+	}
+	return errors.Join(errs...)
+}
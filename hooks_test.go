@@ -0,0 +1,149 @@
+package falcon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_StartHooks(t *testing.T) {
+	var calls []string
+	s := New()
+	s.OnStart(func(ctx context.Context) error {
+		assert.Empty(t, s.Addrs(), "start hooks run before listening")
+		calls = append(calls, "db")
+		return nil
+	})
+	s.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "cache")
+		return nil
+	})
+
+	_, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	// A second listener does not run the hooks again
+	startServer(t, s, runOn(s, "127.0.0.1:0"))
+	assert.Eventually(t, func() bool { return len(s.Addrs()) == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, stop())
+	assert.Equal(t, []string{"db", "cache"}, calls)
+}
+
+func TestServer_StartHookAbortsStartup(t *testing.T) {
+	errDB := errors.New("database unreachable")
+	var calls []string
+	s := New()
+	s.OnStart(func(context.Context) error { calls = append(calls, "first"); return nil })
+	s.OnStart(func(context.Context) error { calls = append(calls, "db"); return errDB })
+	s.OnStart(func(context.Context) error { calls = append(calls, "never"); return nil })
+	s.OnShutdown(func(context.Context) error { calls = append(calls, "teardown"); return nil })
+
+	assert.ErrorIs(t, s.Run(context.Background(), "127.0.0.1:0"), errDB)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.ErrorIs(t, s.Serve(ln), errDB, "later starts report the same error")
+	assert.Empty(t, s.Addrs())
+
+	// Nothing was set up, so there is nothing to tear down
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.Equal(t, []string{"first", "db"}, calls)
+}
+
+func TestServer_ShutdownHooksSkippedIfNeverStarted(t *testing.T) {
+	s := New()
+	s.OnShutdown(func(context.Context) error { return errors.New("closed a pool that was never opened") })
+	assert.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestServer_ShutdownHooks(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	s := blockingServer(started, release)
+
+	var calls []string
+	errFlush := errors.New("flush failed")
+	s.OnShutdown(func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "shutdown hooks get the deadline")
+		calls = append(calls, "telemetry")
+		return errFlush
+	})
+	s.OnShutdown(func(ctx context.Context) error {
+		calls = append(calls, "db")
+		return nil
+	})
+
+	addr, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+	go func() {
+		if res, err := http.Get("http://" + addr + "/slow"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- s.Shutdown(ctx) }()
+
+	// Hooks wait for the in-flight request
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, calls)
+	close(release)
+
+	assert.ErrorIs(t, <-shutdownErr, errFlush)
+	assert.ErrorIs(t, stop(), errFlush)
+	assert.Equal(t, []string{"telemetry", "db"}, calls)
+}
+
+func TestServer_RequestHooks(t *testing.T) {
+	type event struct {
+		kind, path, param string
+		code              int
+	}
+	var events []event
+
+	s := New()
+	s.SetRouting(RoutingConfig{TrailingSlash: PathRedirect})
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) *Response {
+			resp := next(c)
+			if c.Request.URL.Query().Has("deny") {
+				return c.ErrorJSON("denied", nil, http.StatusForbidden)
+			}
+			return resp
+		}
+	})
+	s.GET("/users/:id", func(c *Context) *Response { return c.String(http.StatusOK, "user") })
+	s.OnRequest(func(c *Context) {
+		events = append(events, event{kind: "request", path: c.Request.URL.Path, param: c.Param("id")})
+	})
+	s.OnResponse(func(c *Context, resp *Response) {
+		events = append(events, event{kind: "response", path: c.Request.URL.Path, code: resp.Code})
+	})
+	s.OnError(func(c *Context, resp *Response) {
+		events = append(events, event{kind: "error", path: c.Request.URL.Path, code: resp.Code})
+	})
+
+	for _, target := range []string{"/users/7", "/users/7?deny", "/missing", "/users/7/"} {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	assert.Equal(t, []event{
+		{kind: "request", path: "/users/7", param: "7"},
+		{kind: "response", path: "/users/7", code: http.StatusOK},
+		{kind: "request", path: "/users/7", param: "7"},
+		{kind: "response", path: "/users/7", code: http.StatusForbidden},
+		{kind: "error", path: "/users/7", code: http.StatusForbidden},
+		{kind: "request", path: "/missing"},
+		{kind: "response", path: "/missing", code: http.StatusNotFound},
+		{kind: "error", path: "/missing", code: http.StatusNotFound},
+		{kind: "request", path: "/users/7/"},
+		{kind: "response", path: "/users/7/", code: http.StatusMovedPermanently},
+	}, events)
+}
@@ -0,0 +1,196 @@
+package falcon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// hostRouter holds the routes registered for one host pattern.
+type hostRouter struct {
+	pattern string         // host pattern as given to Host, e.g. ":tenant.example.com"
+	labels  []string       // lower-case labels; ":name" captures a label, "*" matches any
+	static  bool           // whether the pattern has no ":name" or "*" labels
+	router  *server.Router // routes registered through groups for this host
+}
+
+// Host returns a route group whose routes only match requests for the
+// given host. Labels starting with ':' capture a parameter readable with
+// c.Param, and "*" matches any single label. Ports are ignored and
+// matching is case-insensitive. Requests for a host that has no matching
+// route fall back to the routes registered on the server itself.
+// Host panics if the pattern is malformed.
+// Example:
+//
+//	admin := app.Host("admin.example.com")
+//	admin.GET("/", dashboard)
+//
+//	tenants := app.Host(":tenant.example.com")
+//	tenants.GET("/", func(c *falcon.Context) *falcon.Response {
+//		return c.String(200, "tenant "+c.Param("tenant"))
+//	})
+func (s *Server) Host(pattern string) *Group {
+	h, err := s.hostRouter(pattern)
+	if err != nil {
+		panic("falcon: " + err.Error())
+	}
+	g := s.Group("")
+	g.host = h
+	return g
+}
+
+// hostRouter returns the router for pattern, creating it on first use.
+// Static hosts are kept ahead of patterns so they are tried first.
+func (s *Server) hostRouter(pattern string) (*hostRouter, error) {
+	for _, h := range s.hosts {
+		if h.pattern == pattern {
+			return h, nil
+		}
+	}
+
+	h := &hostRouter{pattern: pattern, static: true, router: server.NewRouter()}
+	p := strings.TrimSuffix(strings.ToLower(pattern), ".")
+	if p == "" {
+		return nil, fmt.Errorf("invalid host pattern %q: empty", pattern)
+	}
+	h.labels = strings.Split(p, ".")
+	for _, l := range h.labels {
+		switch {
+		case l == "" || l == ":":
+			return nil, fmt.Errorf("invalid host pattern %q: empty label", pattern)
+		case l[0] == ':' || l == "*":
+			h.static = false
+		case strings.ContainsAny(l, ":*/"):
+			return nil, fmt.Errorf("invalid host pattern %q: bad label %q", pattern, l)
+		}
+	}
+
+	at := len(s.hosts)
+	if h.static {
+		for i, existing := range s.hosts {
+			if !existing.static {
+				at = i
+				break
+			}
+		}
+	}
+	s.hosts = slices.Insert(s.hosts, at, h)
+	return h, nil
+}
+
+// match reports whether host (already lower-case, without port) matches
+// the pattern, appending captured labels to ps.
+func (h *hostRouter) match(host string, ps *server.Params) bool {
+	mark := len(*ps)
+	for i, l := range h.labels {
+		var label string
+		if i == len(h.labels)-1 {
+			label, host = host, ""
+		} else {
+			j := strings.IndexByte(host, '.')
+			if j < 0 {
+				break
+			}
+			label, host = host[:j], host[j+1:]
+		}
+
+		switch {
+		case label == "":
+		case l[0] == ':':
+			*ps = append(*ps, server.Param{Key: l[1:], Value: label})
+			continue
+		case l == "*" || l == label:
+			continue
+		}
+		*ps = (*ps)[:mark]
+		return false
+	}
+	if host != "" || len(h.labels) == 0 {
+		*ps = (*ps)[:mark]
+		return false
+	}
+	return true
+}
+
+// lookupRoute finds the route for method and the request's host and path:
+// first among the host routers whose pattern matches, then on the server
+// router. Host parameters are appended to ps ahead of path parameters.
+// With fold set, static path text is compared ignoring case; with raw
+// set, path is escaped and matched with server.Router.LookupRouteEscaped.
+func (s *Server) lookupRoute(method, host, path string, raw, fold bool, ps *server.Params) *Route {
+	lookup := (*server.Router).LookupRoute
+	switch {
+	case raw:
+		lookup = func(r *server.Router, method, path string, ps *server.Params) *server.Route {
+			return r.LookupRouteEscaped(method, path, ps, fold)
+		}
+	case fold:
+		lookup = (*server.Router).LookupRouteFold
+	}
+	if len(s.hosts) > 0 {
+		host = hostname(host)
+		for _, h := range s.hosts {
+			mark := len(*ps)
+			if !h.match(host, ps) {
+				continue
+			}
+			if rt := lookup(h.router, method, path, ps); rt != nil {
+				return rt
+			}
+			*ps = (*ps)[:mark]
+		}
+	}
+	return lookup(s.router, method, path, ps)
+}
+
+// routerMethods returns the sorted methods registered for path on the
+// server router and on every host router matching host.
+func (s *Server) routerMethods(host, path string, raw, fold bool) []string {
+	methods := (*server.Router).AllowedMethods
+	switch {
+	case raw:
+		methods = func(r *server.Router, path string) []string { return r.AllowedMethodsEscaped(path, fold) }
+	case fold:
+		methods = (*server.Router).AllowedMethodsFold
+	}
+	allowed := methods(s.router, path)
+	if len(s.hosts) > 0 {
+		host = hostname(host)
+		var ps server.Params
+		for _, h := range s.hosts {
+			ps = ps[:0]
+			if h.match(host, &ps) {
+				allowed = append(allowed, methods(h.router, path)...)
+			}
+		}
+		slices.Sort(allowed)
+		allowed = slices.Compact(allowed)
+	}
+	return allowed
+}
+
+// routerFor returns the router routes registered through g belong to.
+func (s *Server) routerFor(g *Group) *server.Router {
+	if g != nil && g.host != nil {
+		return g.host.router
+	}
+	return s.router
+}
+
+// hostname strips the port and any trailing dot from a Host header value
+// and lower-cases it, e.g. "API.Example.com:8080" becomes "api.example.com"
+// and "[FE80::1]:8080" becomes "fe80::1".
+func hostname(host string) string {
+	if strings.HasPrefix(host, "[") {
+		if i := strings.IndexByte(host, ']'); i > 0 {
+			return strings.ToLower(host[1:i])
+		}
+		return strings.ToLower(host)
+	}
+	if i := strings.IndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,129 @@
+package falcon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ascendingheavens/falcon/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveHost(s *Server, method, host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = host
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_HostRouting(t *testing.T) {
+	s := New()
+	s.GET("/", func(c *Context) *Response { return c.String(http.StatusOK, "default") })
+	s.GET("/about", func(c *Context) *Response { return c.String(http.StatusOK, "about") })
+
+	tenants := s.Host(":tenant.example.com")
+	tenants.GET("/", func(c *Context) *Response { return c.String(http.StatusOK, "tenant "+c.Param("tenant")) })
+	tenants.GET("/users/:id", func(c *Context) *Response {
+		return c.String(http.StatusOK, c.Param("tenant")+" user "+c.Param("id"))
+	})
+
+	// Registered after the pattern but tried first because it is static
+	admin := s.Host("admin.example.com")
+	admin.GET("/", func(c *Context) *Response { return c.String(http.StatusOK, "admin") })
+
+	tests := []struct {
+		host, path, want string
+	}{
+		{"admin.example.com", "/", "admin"},
+		{"ADMIN.example.com:8080", "/", "admin"},
+		{"acme.example.com", "/", "tenant acme"},
+		{"acme.example.com", "/users/7", "acme user 7"},
+		{"acme.example.com", "/about", "about"},           // falls back to the default router
+		{"a.b.example.com", "/", "default"},               // one label only
+		{"example.com", "/", "default"},                   // no tenant label
+		{"admin.example.com", "/users/7", "admin user 7"}, // admin has no such route, the pattern does
+	}
+	for _, tt := range tests {
+		rec := serveHost(s, http.MethodGet, tt.host, tt.path)
+		assert.Equal(t, http.StatusOK, rec.Code, tt.host+tt.path)
+		assert.Equal(t, tt.want, rec.Body.String(), tt.host+tt.path)
+	}
+}
+
+func TestServer_HostGroupsMiddlewareAndFallbacks(t *testing.T) {
+	s := New()
+	api := s.Host("api.example.com")
+	api.Use(headerMiddleware("X-API"))
+	v1 := api.Group("/v1")
+	v1.POST("/items", func(c *Context) *Response { return c.String(http.StatusCreated, "created") })
+	api.NotFound(func(c *Context) *Response { return c.String(http.StatusNotFound, "api 404") })
+
+	rec := serveHost(s, http.MethodPost, "api.example.com", "/v1/items")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-API"))
+
+	// Same path on another host is not routed
+	rec = serveHost(s, http.MethodPost, "www.example.com", "/v1/items")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-API"))
+
+	// 405 lists the methods registered for the host
+	rec = serveHost(s, http.MethodGet, "api.example.com", "/v1/items")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "OPTIONS, POST", rec.Header().Get("Allow"))
+
+	// The host NotFound only answers for its own host
+	rec = serveHost(s, http.MethodGet, "api.example.com", "/missing")
+	assert.Equal(t, "api 404", rec.Body.String())
+	rec = serveHost(s, http.MethodGet, "www.example.com", "/missing")
+	assert.NotEqual(t, "api 404", rec.Body.String())
+}
+
+func TestServer_HostRoutesIntrospectionAndURL(t *testing.T) {
+	s := New()
+	s.GET("/users/:id", showUser)
+	s.Host(":tenant.example.com").GET("/users/:id", showUser).Name("tenant.user")
+
+	routes := s.Routes()
+	assert.Len(t, routes, 2)
+	assert.Empty(t, routes[0].Host)
+	assert.Equal(t, ":tenant.example.com", routes[1].Host)
+
+	path, err := s.URL("tenant.user", "id", "3")
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/3", path)
+}
+
+func TestServer_HostInvalidPattern(t *testing.T) {
+	s := New()
+	for _, pattern := range []string{"", "a..b", ":.example.com", "a*b.example.com"} {
+		assert.Panics(t, func() { s.Host(pattern) }, pattern)
+	}
+}
+
+func TestHostname(t *testing.T) {
+	tests := map[string]string{
+		"API.Example.com:8080": "api.example.com",
+		"example.com.":         "example.com",
+		"[FE80::1]:8080":       "fe80::1",
+		"[2001:DB8::1]":        "2001:db8::1",
+	}
+	for host, want := range tests {
+		assert.Equal(t, want, hostname(host), host)
+	}
+}
+
+func TestHostRouter_MatchDoesNotAllocate(t *testing.T) {
+	s := New()
+	s.Host(":tenant.example.com").GET("/", listUsers)
+	h := s.hosts[0]
+
+	ps := make(server.Params, 0, 2)
+	allocs := testing.AllocsPerRun(100, func() {
+		ps = ps[:0]
+		h.match("acme.example.com", &ps)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, "acme", ps.Get("tenant"))
+}
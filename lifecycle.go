@@ -0,0 +1,259 @@
+package falcon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds how long Run and its variants wait for
+// in-flight requests once their context is done or a shutdown signal
+// arrives, unless Config.ShutdownTimeout is set.
+const DefaultShutdownTimeout = 10 * time.Second
+
+// lifecycle tracks the http.Servers started by Run and its variants so
+// Shutdown can stop them together.
+type lifecycle struct {
+	mu      sync.Mutex
+	servers map[*http.Server]net.Addr // running servers and their listen address
+	closing bool                      // set once Shutdown has been called
+	done    chan struct{}             // closed when Shutdown has finished
+	err     error                     // result of Shutdown
+	signals []os.Signal               // signals that trigger a graceful shutdown
+
+	started  chan struct{} // closed when the OnStart hooks have run
+	startErr error         // result of the OnStart hooks
+}
+
+// Run serves HTTP on addr until ctx is done or Shutdown is called, then
+// stops accepting connections and waits up to Config.ShutdownTimeout for
+// in-flight requests. It returns nil after a graceful shutdown and the
+// error otherwise, e.g. when addr is already in use.
+// Example:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	defer stop()
+//	if err := app.Run(ctx, ":8080"); err != nil {
+//		log.Fatal(err)
+//	}
+func (s *Server) Run(ctx context.Context, addr string) error {
+	return s.runServer(ctx, s.newHTTPServer(addr), false, "", "")
+}
+
+// RunTLS serves HTTPS on addr with the given certificate and key files
+// like Run.
+func (s *Server) RunTLS(ctx context.Context, addr, certFile, keyFile string) error {
+	return s.runServer(ctx, s.newHTTPServer(addr), true, certFile, keyFile)
+}
+
+// RunAutoTLS serves HTTPS on :443 with certificates for domain obtained
+// from Let's Encrypt (see StartAutoTLS) like Run. Use
+// RunAutoTLSWithConfig for more hosts or the HTTP-01 challenge server.
+func (s *Server) RunAutoTLS(ctx context.Context, domain string) error {
+	return s.RunAutoTLSWithConfig(ctx, AutoTLSConfig{Hosts: []string{domain}})
+}
+
+// Shutdown gracefully stops every server started with Run, Start or their
+// TLS variants: readiness checks start failing (see Health), and after
+// Config.ShutdownDelay listeners are closed, idle connections dropped, and
+// in-flight requests are given until ctx is done to finish. Connections
+// still open at the deadline are closed and the context error returned.
+// The OnShutdown hooks run afterwards with ctx if the server was started.
+// Run and Start return once Shutdown has finished. A server that has been
+// shut down cannot be started again.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.life.mu.Lock()
+	done := s.life.doneLocked()
+	if s.life.closing {
+		s.life.mu.Unlock()
+		select {
+		case <-done:
+			return s.shutdownErr()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.life.closing = true
+	servers := make([]*http.Server, 0, len(s.life.servers))
+	for srv := range s.life.servers {
+		servers = append(servers, srv)
+	}
+	delay := s.config.ShutdownDelay
+	s.life.mu.Unlock()
+
+	// Keep serving while load balancers notice the failing readiness
+	if delay > 0 && len(servers) > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = srv.Shutdown(ctx); errs[i] != nil {
+				srv.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	errs = append(errs, s.runShutdownHooks(ctx))
+
+	err := errors.Join(errs...)
+	s.life.mu.Lock()
+	s.life.err = err
+	s.life.mu.Unlock()
+	close(done)
+	return err
+}
+
+// ShutdownOnSignal makes Run, Start and their variants shut down
+// gracefully when one of sigs arrives, SIGINT or SIGTERM if none are
+// given, as sent by Ctrl-C or by Kubernetes before stopping a pod.
+// Example:
+//
+//	app.ShutdownOnSignal()
+//	app.Start(":8080") // returns once in-flight requests are done
+func (s *Server) ShutdownOnSignal(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	s.life.mu.Lock()
+	s.life.signals = sigs
+	s.life.mu.Unlock()
+}
+
+// Addrs returns the addresses the server is listening on, e.g. to find
+// the port picked for ":0".
+func (s *Server) Addrs() []net.Addr {
+	s.life.mu.Lock()
+	defer s.life.mu.Unlock()
+	addrs := make([]net.Addr, 0, len(s.life.servers))
+	for _, addr := range s.life.servers {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// runServer listens on srv.Addr and serves srv, with TLS when useTLS is
+// set, until ctx is done or Shutdown is called.
+func (s *Server) runServer(ctx context.Context, srv *http.Server, useTLS bool, certFile, keyFile string) error {
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+	ln, err := listen(srv.Addr, useTLS)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	return s.serve(ctx, srv, ln, func() error {
+		if useTLS {
+			return srv.ServeTLS(ln, certFile, keyFile)
+		}
+		return srv.Serve(ln)
+	})
+}
+
+// listen opens a TCP listener on addr, ":http" or ":https" when empty.
+func listen(addr string, useTLS bool) (net.Listener, error) {
+	if addr == "" {
+		addr = ":http"
+		if useTLS {
+			addr = ":https"
+		}
+	}
+	return net.Listen("tcp", addr)
+}
+
+// serve runs serveFn, which serves srv on ln, and shuts everything down
+// gracefully when ctx is done or a shutdown signal arrives.
+func (s *Server) serve(ctx context.Context, srv *http.Server, ln net.Listener, serveFn func() error) error {
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+	s.life.mu.Lock()
+	if s.life.closing {
+		s.life.mu.Unlock()
+		return http.ErrServerClosed
+	}
+	if s.life.servers == nil {
+		s.life.servers = make(map[*http.Server]net.Addr)
+	}
+	s.life.servers[srv] = ln.Addr()
+	sigs := s.life.signals
+	s.life.mu.Unlock()
+	defer func() {
+		s.life.mu.Lock()
+		delete(s.life.servers, srv)
+		s.life.mu.Unlock()
+	}()
+
+	if len(sigs) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, sigs...)
+		defer stop()
+	}
+
+	s.compiled()
+	errc := make(chan error, 1)
+	go func() { errc <- serveFn() }()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		// Shutdown was called elsewhere; wait until it has drained
+		<-s.shutdownDone()
+		return s.shutdownErr()
+	case <-ctx.Done():
+		sctx := context.WithoutCancel(ctx)
+		if timeout := s.Config().ShutdownTimeout; timeout > 0 {
+			var cancel context.CancelFunc
+			sctx, cancel = context.WithTimeout(sctx, timeout)
+			defer cancel()
+		}
+		err := s.Shutdown(sctx)
+		<-errc
+		return err
+	}
+}
+
+// shuttingDown reports whether Shutdown has been called.
+func (s *Server) shuttingDown() bool {
+	s.life.mu.Lock()
+	defer s.life.mu.Unlock()
+	return s.life.closing
+}
+
+// doneLocked returns the channel closed when Shutdown finishes, creating
+// it if needed. s.life.mu must be held.
+func (l *lifecycle) doneLocked() chan struct{} {
+	if l.done == nil {
+		l.done = make(chan struct{})
+	}
+	return l.done
+}
+
+func (s *Server) shutdownDone() chan struct{} {
+	s.life.mu.Lock()
+	defer s.life.mu.Unlock()
+	return s.life.doneLocked()
+}
+
+func (s *Server) shutdownErr() error {
+	s.life.mu.Lock()
+	defer s.life.mu.Unlock()
+	return s.life.err
+}
@@ -0,0 +1,183 @@
+package falcon
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForAddr waits until s is listening and returns its address.
+func waitForAddr(t *testing.T, s *Server) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if addrs := s.Addrs(); len(addrs) > 0 {
+			return addrs[0].String()
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("server did not start listening")
+	return ""
+}
+
+// startServer runs s in the background through run, e.g. s.Run or s.RunTLS,
+// with a context that stop cancels, and waits until it is listening. It
+// returns the first address and stop, which returns the result of run.
+// The server is stopped at the end of the test if stop was not called.
+func startServer(t *testing.T, s *Server, run func(ctx context.Context) error) (addr string, stop func() error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- run(ctx) }()
+	stop = sync.OnceValue(func() error {
+		cancel()
+		select {
+		case err := <-errc:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Error("server did not stop")
+			return nil
+		}
+	})
+	t.Cleanup(func() { _ = stop() })
+	return waitForAddr(t, s), stop
+}
+
+// runOn returns a run func for startServer serving HTTP on addr.
+func runOn(s *Server, addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error { return s.Run(ctx, addr) }
+}
+
+// blockingServer returns a server whose /slow handler signals started and
+// then waits for release.
+func blockingServer(started chan<- struct{}, release <-chan struct{}) *Server {
+	s := New()
+	s.GET("/slow", func(c *Context) *Response {
+		started <- struct{}{}
+		<-release
+		return c.String(http.StatusOK, "done")
+	})
+	return s
+}
+
+func TestServer_RunStopsWithContext(t *testing.T) {
+	s := New()
+	s.GET("/", func(c *Context) *Response { return c.String(http.StatusOK, "ok") })
+
+	addr, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	res, err := http.Get("http://" + addr + "/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "ok", string(body))
+
+	assert.NoError(t, stop())
+	assert.Empty(t, s.Addrs())
+
+	_, err = http.Get("http://" + addr + "/")
+	assert.Error(t, err)
+}
+
+func TestServer_RunReturnsListenError(t *testing.T) {
+	s := New()
+	addr, _ := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	err := New().Run(context.Background(), addr)
+	assert.ErrorContains(t, err, "address already in use")
+}
+
+func TestServer_ShutdownDrainsInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	s := blockingServer(started, release)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- s.Run(context.Background(), "127.0.0.1:0") }()
+	addr := waitForAddr(t, s)
+
+	type result struct {
+		body string
+		err  error
+	}
+	resc := make(chan result, 1)
+	go func() {
+		res, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			resc <- result{err: err}
+			return
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		resc <- result{body: string(body)}
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- s.Shutdown(context.Background()) }()
+
+	// New connections are refused while the in-flight request finishes
+	fresh := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	assert.Eventually(t, func() bool {
+		_, err := fresh.Get("http://" + addr + "/missing")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown returned before the request finished: %v", err)
+	case err := <-runErr:
+		t.Fatalf("Run returned before the request finished: %v", err)
+	default:
+	}
+
+	close(release)
+	res := <-resc
+	require.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+	assert.NoError(t, <-shutdownErr)
+	assert.NoError(t, <-runErr)
+
+	// A server that was shut down does not start again
+	assert.ErrorIs(t, s.Run(context.Background(), "127.0.0.1:0"), http.ErrServerClosed)
+}
+
+func TestServer_ShutdownDeadline(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	s := blockingServer(started, release)
+
+	addr, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	go func() {
+		if res, err := http.Get("http://" + addr + "/slow"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	err := s.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(begin), 2*time.Second)
+	assert.ErrorIs(t, stop(), context.DeadlineExceeded)
+}
+
+func TestServer_ShutdownStopsAllServers(t *testing.T) {
+	s := New()
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() { errs <- s.Run(context.Background(), "127.0.0.1:0") }()
+	}
+	assert.Eventually(t, func() bool { return len(s.Addrs()) == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.NoError(t, <-errs)
+	assert.NoError(t, <-errs)
+}
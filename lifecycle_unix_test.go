@@ -0,0 +1,23 @@
+//go:build unix
+
+package falcon
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ShutdownOnSignal(t *testing.T) {
+	s := New()
+	s.ShutdownOnSignal(syscall.SIGUSR1)
+
+	_, stop := startServer(t, s, runOn(s, "127.0.0.1:0"))
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return len(s.Addrs()) == 0 }, 5*time.Second, 5*time.Millisecond,
+		"server did not shut down on signal")
+	assert.NoError(t, stop())
+}
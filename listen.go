@@ -0,0 +1,85 @@
+package falcon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+)
+
+// Serve serves HTTP on ln until Shutdown is called, which stops every
+// listener of the server together. Call it once per listener to serve the
+// same app on several of them, e.g. a public and an admin port. It returns
+// nil after a graceful shutdown.
+// Example:
+//
+//	ln, _ := net.Listen("tcp", "127.0.0.1:9090")
+//	go app.Serve(ln)
+//	app.Start(":8080")
+func (s *Server) Serve(ln net.Listener) error {
+	srv := s.newHTTPServer(ln.Addr().String())
+	defer ln.Close()
+	return s.serve(context.Background(), srv, ln, func() error { return srv.Serve(ln) })
+}
+
+// ServeTLS serves HTTPS on ln with the given certificate and key files
+// like Serve.
+func (s *Server) ServeTLS(ln net.Listener, certFile, keyFile string) error {
+	srv := s.newHTTPServer(ln.Addr().String())
+	defer ln.Close()
+	return s.serve(context.Background(), srv, ln, func() error { return srv.ServeTLS(ln, certFile, keyFile) })
+}
+
+// RunUnix serves HTTP on the Unix domain socket at path like Run, with
+// the socket file's permissions set to mode. A stale socket file left by a
+// previous process is replaced; a socket still in use is an error. The
+// file is removed on shutdown.
+func (s *Server) RunUnix(ctx context.Context, path string, mode os.FileMode) error {
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	if err := os.Chmod(path, mode); err != nil {
+		return err
+	}
+
+	srv := s.newHTTPServer(path)
+	return s.serve(ctx, srv, ln, func() error { return srv.Serve(ln) })
+}
+
+// StartUnix runs the HTTP server on the Unix domain socket at path, e.g.
+// for a local sidecar proxy, and terminates the program if it fails like
+// Start.
+// Example:
+//
+//	app.StartUnix("/run/app/http.sock", 0o660)
+func (s *Server) StartUnix(path string, mode os.FileMode) {
+	log.Printf("Starting server on unix:%s", path)
+	if err := s.RunUnix(context.Background(), path, mode); err != nil {
+		logFatal(err)
+	}
+}
+
+// removeStaleSocket removes the socket file at path unless a server still
+// accepts connections on it. Other kinds of files are left for net.Listen
+// to fail on.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode().Type() != os.ModeSocket {
+		return nil
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("falcon: socket %s is in use", path)
+	}
+	return os.Remove(path)
+}
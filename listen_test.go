@@ -0,0 +1,47 @@
+package falcon
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ServeSeveralListeners(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir(), "localhost")
+	s := New()
+	s.GET("/", func(c *Context) *Response { return c.String(http.StatusOK, "hello") })
+
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	secure, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	errs := make(chan error, 2)
+	go func() { errs <- s.Serve(plain) }()
+	go func() { errs <- s.ServeTLS(secure, certFile, keyFile) }()
+	assert.Eventually(t, func() bool { return len(s.Addrs()) == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	for _, url := range []string{"http://" + plain.Addr().String() + "/", "https://" + secure.Addr().String() + "/"} {
+		res, err := client.Get(url)
+		require.NoError(t, err, url)
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, "hello", string(body), url)
+	}
+
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.NoError(t, <-errs)
+	assert.NoError(t, <-errs)
+	for _, ln := range []net.Listener{plain, secure} {
+		_, err := net.Dial("tcp", ln.Addr().String())
+		assert.Error(t, err, "listener closed")
+	}
+}
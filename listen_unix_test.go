@@ -0,0 +1,73 @@
+//go:build unix
+
+package falcon
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+func TestServer_RunUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+	s := New()
+	s.GET("/", func(c *Context) *Response { return c.String(http.StatusOK, "over unix") })
+
+	_, stop := startServer(t, s, func(ctx context.Context) error { return s.RunUnix(ctx, path, 0o660) })
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	res, err := unixClient(path).Get("http://sidecar/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "over unix", string(body))
+
+	assert.NoError(t, stop())
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist, "socket file removed on shutdown")
+}
+
+func TestServer_RunUnixSocketFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// A stale socket left by a crashed process is replaced
+	stale := filepath.Join(dir, "stale.sock")
+	ln, err := net.Listen("unix", stale)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	s := New()
+	_, stop := startServer(t, s, func(ctx context.Context) error { return s.RunUnix(ctx, stale, 0o600) })
+
+	// A socket still in use is not taken over
+	err = New().RunUnix(context.Background(), stale, 0o600)
+	assert.ErrorContains(t, err, "in use")
+
+	assert.NoError(t, stop())
+
+	// Regular files are never removed
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	assert.Error(t, New().RunUnix(context.Background(), file, 0o600))
+	_, err = os.Stat(file)
+	assert.NoError(t, err)
+}
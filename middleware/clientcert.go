@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// ClientCertAuth returns a middleware that only lets requests through
+// whose verified TLS client certificate matches cfg (see
+// server.Context.ClientCert). Requests without a verified certificate get
+// a 401 Unauthorized response, those with a certificate that does not
+// match get a 403 Forbidden response.
+//
+// Example usage:
+//
+//	internal := app.Group("/internal")
+//	internal.Use(middleware.ClientCertAuth(middleware.ClientCertConfig{
+//		URIs: []string{"spiffe://example.org/ns/prod/sa/*"},
+//	}))
+func ClientCertAuth(cfg ClientCertConfig) Middleware {
+	return func(next server.HandlerFunc) server.HandlerFunc {
+		return func(c *server.Context) *server.Response {
+			cert := c.ClientCert()
+			if cert == nil {
+				return c.ErrorJSON("Client certificate required", nil, http.StatusUnauthorized)
+			}
+			if !matchAny(cfg.Subjects, cert.CommonName) &&
+				!matchAny(cfg.DNSNames, cert.DNSNames...) &&
+				!matchAny(cfg.URIs, cert.URIs...) &&
+				!matchAny(cfg.Emails, cert.Emails...) {
+				return c.ErrorJSON("Client certificate not authorized", nil, http.StatusForbidden)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireClientCert returns a ClientCertAuth middleware that matches every
+// pattern against the subject common name and all SANs of the client
+// certificate.
+//
+// Example usage:
+//
+//	app.Use(middleware.RequireClientCert("spiffe://example.org/ns/prod/*", "billing"))
+func RequireClientCert(patterns ...string) Middleware {
+	return ClientCertAuth(ClientCertConfig{Subjects: patterns, DNSNames: patterns, URIs: patterns, Emails: patterns})
+}
+
+// matchAny reports whether any of names matches any of patterns.
+func matchAny(patterns []string, names ...string) bool {
+	for _, p := range patterns {
+		for _, name := range names {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ascendingheavens/falcon/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func runClientCertAuth(mw Middleware, cert *server.ClientCert) *server.Response {
+	c := &server.Context{Writer: httptest.NewRecorder(), Request: httptest.NewRequest(http.MethodGet, "/", nil)}
+	if cert != nil {
+		c.Set(server.ClientCertKey, cert)
+	}
+	return mw(func(c *server.Context) *server.Response {
+		return &server.Response{Success: true, Code: http.StatusOK}
+	})(c)
+}
+
+func TestClientCertAuth(t *testing.T) {
+	mw := ClientCertAuth(ClientCertConfig{
+		Subjects: []string{"billing-*"},
+		URIs:     []string{"spiffe://example.org/ns/prod/sa/*"},
+	})
+
+	tests := []struct {
+		name string
+		cert *server.ClientCert
+		code int
+	}{
+		{"no certificate", nil, http.StatusUnauthorized},
+		{"subject", &server.ClientCert{CommonName: "billing-api"}, http.StatusOK},
+		{"spiffe id", &server.ClientCert{CommonName: "x", URIs: []string{"spiffe://example.org/ns/prod/sa/orders"}}, http.StatusOK},
+		{"star does not cross slash", &server.ClientCert{URIs: []string{"spiffe://example.org/ns/prod/sa/orders/extra"}}, http.StatusForbidden},
+		{"other namespace", &server.ClientCert{URIs: []string{"spiffe://example.org/ns/dev/sa/orders"}}, http.StatusForbidden},
+		{"dns name not configured", &server.ClientCert{DNSNames: []string{"billing-api"}}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, runClientCertAuth(mw, tt.cert).Code)
+		})
+	}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	mw := RequireClientCert("*.billing.svc", "ops@example.com")
+
+	assert.Equal(t, http.StatusOK, runClientCertAuth(mw, &server.ClientCert{DNSNames: []string{"api.billing.svc"}}).Code)
+	assert.Equal(t, http.StatusOK, runClientCertAuth(mw, &server.ClientCert{Emails: []string{"ops@example.com"}}).Code)
+	assert.Equal(t, http.StatusForbidden, runClientCertAuth(mw, &server.ClientCert{CommonName: "orders"}).Code)
+}
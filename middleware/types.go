@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/ascendingheavens/falcon/server"
@@ -11,12 +13,21 @@ import (
 // Examples include logging, authentication, profiling, or panic recovery.
 type Middleware func(server.HandlerFunc) server.HandlerFunc
 
-// ConditionalMiddleware pairs a middleware with a path pattern.
-// The middleware is only applied if the request path matches the pattern.
-// Patterns can include a wildcard '*' at the end to match any subpath.
+// ConditionalMiddleware pairs a middleware with the requests it applies to.
+// Every filter that is set must match; unset filters match everything.
+//
+// Pattern is a glob matched segment by segment against the request path
+// and everything below it: "/api" covers "/api" and "/api/users" but not
+// "/apiary". Within a segment the path.Match syntax applies ("*.json"),
+// a "*" segment matches exactly one segment and "**" matches any number
+// of segments, e.g. "/api/*/admin/**" or "/api/**/export".
 type ConditionalMiddleware struct {
-	Pattern    string     // The URL path pattern to match, e.g., "/api/v1/*"
-	Middleware Middleware // The middleware function to apply when the pattern matches
+	Pattern    string                   // Path glob, e.g. "/api/v1/*"; empty matches every path
+	Regexp     *regexp.Regexp           // Optional expression the request path must match
+	Methods    []string                 // Optional HTTP methods; GET also covers HEAD
+	Hosts      []string                 // Optional host globs without port, e.g. "*.example.com"
+	Match      func(*http.Request) bool // Optional predicate for anything else
+	Middleware Middleware               // The middleware function to apply when the request matches
 }
 
 // CORSConfig defines configuration for Cross-Origin Resource Sharing (CORS).
@@ -39,3 +50,14 @@ type CSRFConfig struct {
 	CookieSecure   bool                                          // Whether the cookie is Secure
 	CookieHTTPOnly bool                                          // Whether the cookie is HttpOnly
 }
+
+// ClientCertConfig defines which verified TLS client certificates
+// ClientCertAuth lets through. A certificate is authorized when any of
+// its names matches any pattern of the corresponding list. Patterns use
+// the path.Match syntax, so "*" does not cross a "/" in URIs.
+type ClientCertConfig struct {
+	Subjects []string // Subject common names, e.g. "billing-*"
+	DNSNames []string // DNS SANs, e.g. "*.billing.svc.cluster.local"
+	URIs     []string // URI SANs, e.g. "spiffe://example.org/ns/prod/sa/*"
+	Emails   []string // Email SANs, e.g. "*@example.com"
+}
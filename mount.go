@@ -0,0 +1,107 @@
+package falcon
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// MountConfig defines how a standard http.Handler is mounted under a prefix.
+type MountConfig struct {
+	// KeepPrefix passes the request path through unchanged instead of
+	// stripping the mount prefix. Handlers that route on the full path,
+	// such as http.DefaultServeMux serving net/http/pprof under
+	// "/debug/pprof/", need this.
+	KeepPrefix bool
+}
+
+// Mount serves every request under prefix, for any method, with the given
+// http.Handler. The prefix is stripped from the request path, so a handler
+// mounted at "/admin" sees "/admin/users" as "/users". Another Falcon
+// Server can be mounted the same way. Mounted handlers run through the
+// Falcon middleware chain like any route.
+// Example:
+//
+//	app.Mount("/metrics", promhttp.Handler())
+//	app.Mount("/admin", adminApp)
+func (s *Server) Mount(prefix string, h http.Handler) []*Route {
+	return s.MountWithConfig(prefix, h, MountConfig{})
+}
+
+// MountWithConfig mounts h under prefix like Mount, using cfg.
+// Example:
+//
+//	app.MountWithConfig("/debug/pprof", http.DefaultServeMux, falcon.MountConfig{KeepPrefix: true})
+func (s *Server) MountWithConfig(prefix string, h http.Handler, cfg MountConfig) []*Route {
+	return mount(s, prefix, h, cfg)
+}
+
+// Mount serves every request under the group prefix plus prefix with the
+// given http.Handler, stripping the full prefix. Group middleware applies.
+func (g *Group) Mount(prefix string, h http.Handler) []*Route {
+	return g.MountWithConfig(prefix, h, MountConfig{})
+}
+
+// MountWithConfig mounts h under the group like Mount, using cfg.
+func (g *Group) MountWithConfig(prefix string, h http.Handler, cfg MountConfig) []*Route {
+	return mount(g, prefix, h, cfg)
+}
+
+// mount registers the prefix itself and a catch-all below it for every
+// method on target. strip is the full path prefix removed from requests.
+func mount(target Handler, prefix string, h http.Handler, cfg MountConfig) []*Route {
+	prefix = strings.TrimSuffix(prefix, "/")
+	strip := prefix
+	if g, ok := target.(*Group); ok {
+		strip = strings.TrimSuffix(g.Prefix, "/") + prefix
+	}
+
+	handler := mountedHandler(strip, h, cfg)
+	routes := target.Any(prefix+"/*", handler)
+	if prefix != "" {
+		routes = append(routes, target.Any(prefix, handler)...)
+	}
+	return routes
+}
+
+// mountedHandler adapts an http.Handler to a HandlerFunc. The mounted
+// handler always owns the response, so the Context is marked handled and
+// the returned Response carries the status it wrote.
+func mountedHandler(strip string, h http.Handler, cfg MountConfig) server.HandlerFunc {
+	return func(c *server.Context) *server.Response {
+		r := c.Request
+		if !cfg.KeepPrefix {
+			r = stripPrefix(r, strip)
+		}
+
+		rec := &server.StatusRecorder{ResponseWriter: c.Writer}
+		h.ServeHTTP(rec, r)
+		c.Handled = true
+
+		code := rec.Status()
+		return &server.Response{Success: code < http.StatusBadRequest, Message: http.StatusText(code), Code: code}
+	}
+}
+
+// stripPrefix returns a shallow copy of r with prefix removed from the URL
+// path (and raw path, when set). The result always starts with '/'.
+func stripPrefix(r *http.Request, prefix string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = ensureLeadingSlash(strings.TrimPrefix(r.URL.Path, prefix))
+	if r.URL.RawPath != "" {
+		r2.URL.RawPath = ensureLeadingSlash(strings.TrimPrefix(r.URL.RawPath, prefix))
+	}
+	return r2
+}
+
+func ensureLeadingSlash(p string) string {
+	if p == "" || p[0] != '/' {
+		return "/" + p
+	}
+	return p
+}
@@ -0,0 +1,114 @@
+package falcon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Mount(t *testing.T) {
+	s := New()
+	var seen *Response
+	var handled bool
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) *Response {
+			seen = next(c)
+			handled = c.Handled
+			return seen
+		}
+	})
+
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Raw-Path", r.URL.RawPath)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(r.Method + " legacy"))
+	})
+	s.Mount("/legacy/", legacy)
+
+	tests := []struct {
+		method, target string
+		code           int
+		path           string
+	}{
+		{http.MethodGet, "/legacy", http.StatusOK, "/"},
+		{http.MethodGet, "/legacy/", http.StatusOK, "/"},
+		{http.MethodPost, "/legacy/a/b", http.StatusOK, "/a/b"},
+		{http.MethodGet, "/legacy/missing", http.StatusNotFound, "/missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			seen, handled = nil, false
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Equal(t, tt.path, rec.Header().Get("X-Path"))
+			assert.True(t, handled)
+			if assert.NotNil(t, seen) {
+				assert.Equal(t, tt.code, seen.Code)
+			}
+		})
+	}
+
+	t.Run("raw path is stripped too", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/legacy/a%2Fb", nil))
+		assert.Equal(t, "/a/b", rec.Header().Get("X-Path"))
+		assert.Equal(t, "/a%2Fb", rec.Header().Get("X-Raw-Path"))
+	})
+
+	t.Run("outside the prefix is not mounted", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/legacyx", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestServer_MountKeepPrefix(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/vars/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	})
+
+	s := New()
+	s.MountWithConfig("/debug/vars", mux, MountConfig{KeepPrefix: true})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars/memstats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/debug/vars/memstats", rec.Body.String())
+}
+
+func TestServer_MountSubApplication(t *testing.T) {
+	admin := New()
+	admin.Use(headerMiddleware("X-Admin"))
+	admin.GET("/users/:id", func(c *Context) *Response {
+		return c.String(http.StatusOK, "admin user "+c.Param("id"))
+	})
+
+	app := New()
+	app.Use(headerMiddleware("X-App"))
+	api := app.Group("/api")
+	api.Use(headerMiddleware("X-API"))
+	api.Mount("/admin", admin)
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/users/3", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "admin user 3", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("X-App"))
+	assert.Equal(t, "1", rec.Header().Get("X-API"))
+	assert.Equal(t, "1", rec.Header().Get("X-Admin"))
+
+	// The sub-application answers its own 404s
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/admin/nothing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
@@ -0,0 +1,77 @@
+package falcon
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// RouteInfo is an alias to server.RouteInfo, describing a registered route:
+// method, full path (including any group prefix), name, handler function
+// name, number of middleware and parameter constraints.
+type RouteInfo = server.RouteInfo
+
+// Routes returns every route registered on the server, in registration
+// order. Paths include the group prefix, Host is set for routes
+// registered through Host, and the middleware count covers
+// global and group middleware plus conditional middleware that may match.
+// Automatic HEAD and OPTIONS handling is not listed.
+func (s *Server) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(s.routes))
+	for _, e := range s.routes {
+		info := e.route.Info()
+		info.Middlewares = len(s.middlewareFor(e.group, e.route))
+		if e.group != nil && e.group.host != nil {
+			info.Host = e.group.host.pattern
+		}
+		routes = append(routes, info)
+	}
+	return routes
+}
+
+// PrintRoutes writes the route table to w, one route per line. Routes
+// registered through Host are listed with the host before the path.
+// Example: app.PrintRoutes(os.Stdout) before app.Start(":8080")
+func (s *Server) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHANDLER\tMIDDLEWARE")
+	for _, rt := range s.Routes() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", rt.Method, rt.Host+rt.Path, rt.Name, rt.Handler, rt.Middlewares)
+	}
+	return tw.Flush()
+}
+
+// DebugRoutes registers a GET endpoint at path that lists the route table.
+// It answers with an HTML table when the client accepts text/html and with
+// the JSON Response envelope (routes in Details) otherwise. Only mount it
+// where the route table is safe to expose.
+// Example: app.DebugRoutes("/debug/routes")
+func (s *Server) DebugRoutes(path string) *Route {
+	return s.GET(path, func(c *Context) *Response {
+		routes := s.Routes()
+		if strings.Contains(c.Request.Header.Get("Accept"), "text/html") {
+			var b strings.Builder
+			if err := routesTemplate.Execute(&b, routes); err != nil {
+				return c.ErrorJSON("Failed to render routes", err.Error(), http.StatusInternalServerError)
+			}
+			return c.HTML(http.StatusOK, b.String())
+		}
+		return &Response{Success: true, Message: "Routes", Details: routes, Code: http.StatusOK}
+	})
+}
+
+// routesTemplate renders the route table for DebugRoutes.
+var routesTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html><head><title>Routes</title></head><body>
+<table>
+<tr><th>Method</th><th>Path</th><th>Name</th><th>Handler</th><th>Middleware</th></tr>
+{{- range . }}
+<tr><td>{{ .Method }}</td><td>{{ .Host }}{{ .Path }}</td><td>{{ .Name }}</td><td>{{ .Handler }}</td><td>{{ .Middlewares }}</td></tr>
+{{- end }}
+</table>
+</body></html>`))
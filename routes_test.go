@@ -0,0 +1,77 @@
+package falcon
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listUsers(c *Context) *Response { return nil }
+func showUser(c *Context) *Response  { return nil }
+
+func TestServer_Routes(t *testing.T) {
+	s := New()
+	s.Use(headerMiddleware("X-Global"))
+	s.UseIf("/api/*", headerMiddleware("X-API"))
+	s.GET("/health", listUsers)
+
+	api := s.Group("/api/v1")
+	api.Use(headerMiddleware("X-V1"))
+	api.GET("/users", listUsers).Name("users.list")
+	api.GET("/users/:id<int>", showUser).Name("users.show")
+
+	assert.Equal(t, []RouteInfo{
+		{Method: http.MethodGet, Path: "/health", Handler: "github.com/ascendingheavens/falcon.listUsers", Middlewares: 1},
+		{Method: http.MethodGet, Path: "/api/v1/users", Name: "users.list", Handler: "github.com/ascendingheavens/falcon.listUsers", Middlewares: 3},
+		{Method: http.MethodGet, Path: "/api/v1/users/:id<int>", Name: "users.show", Handler: "github.com/ascendingheavens/falcon.showUser", Middlewares: 3, Constraints: map[string]string{"id": "int"}},
+	}, s.Routes())
+}
+
+func TestServer_PrintRoutes(t *testing.T) {
+	s := New()
+	s.GET("/users/:id", showUser).Name("user.show")
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.PrintRoutes(&buf))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), "METHOD")
+	assert.Contains(t, string(lines[1]), "/users/:id")
+	assert.Contains(t, string(lines[1]), "user.show")
+	assert.Contains(t, string(lines[1]), "falcon.showUser")
+}
+
+func TestServer_DebugRoutes(t *testing.T) {
+	s := New()
+	s.DebugRoutes("/debug/routes")
+	s.POST("/users", listUsers)
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Success bool        `json:"success"`
+			Details []RouteInfo `json:"details"`
+		}
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+		assert.True(t, body.Success)
+		assert.Len(t, body.Details, 2)
+		assert.Equal(t, "/users", body.Details[1].Path)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+		req.Header.Set("Accept", "text/html")
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/html", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "<td>/users</td>")
+	})
+}
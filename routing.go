@@ -0,0 +1,212 @@
+package falcon
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// PathPolicy decides what happens to a request whose path only matches a
+// route once it is normalized.
+type PathPolicy uint8
+
+const (
+	// PathStrict leaves the path alone, so the request gets a 404.
+	PathStrict PathPolicy = iota
+	// PathRedirect redirects to the matching route's path with 301 for
+	// GET and HEAD and 308 for other methods, which keeps the method and body.
+	PathRedirect
+	// PathMatch serves the matching route without redirecting. Handlers
+	// and middleware see the request with the route's canonical path.
+	PathMatch
+)
+
+// RoutingConfig controls how request paths that do not match a route
+// exactly are handled. The zero value matches paths strictly.
+type RoutingConfig struct {
+	// TrailingSlash handles "/users/" for a "/users" route and vice versa.
+	TrailingSlash PathPolicy
+
+	// CleanPath handles paths that path.Clean changes, such as "//users"
+	// or "/a/../users".
+	CleanPath PathPolicy
+
+	// CaseInsensitive handles paths that only match ignoring ASCII case,
+	// such as "/Users" for a "/users" route. Parameter values keep the
+	// case they were sent with.
+	CaseInsensitive PathPolicy
+}
+
+// SetRouting sets how paths that only match a route after normalization
+// are handled. When several fixes are needed to find a route, the request
+// is redirected if any of them is set to PathRedirect.
+// Example:
+//
+//	app.SetRouting(falcon.RoutingConfig{
+//		TrailingSlash:   falcon.PathRedirect,
+//		CleanPath:       falcon.PathRedirect,
+//		CaseInsensitive: falcon.PathMatch,
+//	})
+func (s *Server) SetRouting(cfg RoutingConfig) {
+	s.routing = cfg
+}
+
+// routingPath returns the path requests are routed on: RawPath when the
+// request path contains escapes that Path cannot represent, such as an
+// encoded slash in "/files/a%2Fb", so they stay inside one parameter.
+// Static text and constraints are still compared with the unescaped path.
+func routingPath(u *url.URL) (p string, raw bool) {
+	if u.RawPath != "" {
+		return u.RawPath, true
+	}
+	return u.Path, false
+}
+
+// withPath returns a shallow copy of r with its URL path replaced by p,
+// which is escaped when raw is true.
+func withPath(r *http.Request, p string, raw bool) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path, r2.URL.RawPath = p, ""
+	if raw {
+		if v, err := url.PathUnescape(p); err == nil {
+			r2.URL.Path = v
+		}
+		r2.URL.RawPath = p
+	}
+	return r2
+}
+
+// unescapeParams decodes parameter values captured from a raw path.
+// Values that are not valid escapes are kept as they are.
+func unescapeParams(ps server.Params) {
+	for i := range ps {
+		if v, err := url.PathUnescape(ps[i].Value); err == nil {
+			ps[i].Value = v
+		}
+	}
+}
+
+// find looks up the route for method, falling back to the GET route for
+// HEAD requests, in which case head is true. raw reports whether p is
+// escaped, as returned by routingPath.
+func (s *Server) find(method, host, p string, raw, fold bool, ps *server.Params) (rt *Route, head bool) {
+	if rt = s.lookupRoute(method, host, p, raw, fold, ps); rt != nil {
+		return rt, false
+	}
+	if method == http.MethodHead {
+		if rt = s.lookupRoute(http.MethodGet, host, p, raw, fold, ps); rt != nil {
+			return rt, true
+		}
+	}
+	return nil, false
+}
+
+// normalize looks for a route matching a normalized form of p after the
+// exact lookup failed. It returns the route, its canonical path and the
+// policy for the fixes that were needed.
+func (s *Server) normalize(method, host, p string, raw bool, ps *server.Params) (rt *Route, head bool, canonical string, policy PathPolicy) {
+	canonical, fold, policy, ok := s.normalized(p, func(p string, fold bool) bool {
+		rt, head = s.find(method, host, p, raw, fold, ps)
+		return rt != nil
+	})
+	if !ok {
+		return nil, false, "", PathStrict
+	}
+	if fold {
+		canonical = rt.Expand(*ps)
+	}
+	return rt, head, canonical, policy
+}
+
+// normalized tries the normalized forms of p enabled by the routing
+// config until match accepts one: the cleaned path, the other
+// trailing-slash form, then a case-insensitive match of either. It returns
+// the accepted form, whether it was matched ignoring case, and the policy
+// for the fixes that were needed.
+func (s *Server) normalized(p string, match func(p string, fold bool) bool) (fixed string, fold bool, policy PathPolicy, ok bool) {
+	cfg := s.routing
+
+	if cfg.CleanPath != PathStrict {
+		if cleaned := cleanPath(p); cleaned != p {
+			p, policy = cleaned, cfg.CleanPath
+			if match(p, false) {
+				return p, false, policy, true
+			}
+		}
+	}
+
+	toggled := ""
+	if cfg.TrailingSlash != PathStrict {
+		toggled = toggleSlash(p)
+		if toggled != "" && match(toggled, false) {
+			return toggled, false, combine(policy, cfg.TrailingSlash), true
+		}
+	}
+
+	if cfg.CaseInsensitive != PathStrict {
+		policy = combine(policy, cfg.CaseInsensitive)
+		if match(p, true) {
+			return p, true, policy, true
+		}
+		if toggled != "" && match(toggled, true) {
+			return toggled, true, combine(policy, cfg.TrailingSlash), true
+		}
+	}
+	return "", false, PathStrict, false
+}
+
+// combine returns the policy for applying two fixes: a redirect if either
+// asks for one.
+func combine(a, b PathPolicy) PathPolicy {
+	if a == PathRedirect || b == PathRedirect {
+		return PathRedirect
+	}
+	return max(a, b)
+}
+
+// cleanPath is path.Clean that keeps a trailing slash, so the trailing
+// slash is left to the TrailingSlash policy.
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// toggleSlash adds or removes the trailing slash of p, or returns "" for
+// the root path.
+func toggleSlash(p string) string {
+	switch {
+	case p == "/":
+		return ""
+	case strings.HasSuffix(p, "/"):
+		return p[:len(p)-1]
+	default:
+		return p + "/"
+	}
+}
+
+// redirectToCanonical redirects r to canonical, keeping the query string,
+// and returns the status code sent. canonical is escaped unless it was
+// taken from the raw path.
+func redirectToCanonical(w http.ResponseWriter, r *http.Request, canonical string, raw bool) int {
+	if !raw {
+		canonical = (&url.URL{Path: canonical}).EscapedPath()
+	}
+	if r.URL.RawQuery != "" {
+		canonical += "?" + r.URL.RawQuery
+	}
+	code := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	http.Redirect(w, r, canonical, code)
+	return code
+}
@@ -0,0 +1,183 @@
+package falcon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newNormalizationServer(cfg RoutingConfig) *Server {
+	s := New()
+	s.SetRouting(cfg)
+	s.GET("/users", func(c *Context) *Response { return c.String(http.StatusOK, "users") })
+	s.GET("/docs/", func(c *Context) *Response { return c.String(http.StatusOK, "docs") })
+	s.GET("/users/:id", func(c *Context) *Response { return c.String(http.StatusOK, "user "+c.Param("id")) })
+	s.POST("/users", func(c *Context) *Response { return c.String(http.StatusCreated, "created") })
+	return s
+}
+
+func TestServer_StrictRoutingByDefault(t *testing.T) {
+	s := newNormalizationServer(RoutingConfig{})
+	for _, path := range []string{"/users/", "/docs", "//users", "/Users"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+}
+
+func TestServer_RoutingRedirects(t *testing.T) {
+	s := newNormalizationServer(RoutingConfig{
+		TrailingSlash:   PathRedirect,
+		CleanPath:       PathRedirect,
+		CaseInsensitive: PathRedirect,
+	})
+
+	tests := []struct {
+		method, target string
+		code           int
+		location       string
+	}{
+		{http.MethodGet, "/users/", http.StatusMovedPermanently, "/users"},
+		{http.MethodGet, "/docs?page=2", http.StatusMovedPermanently, "/docs/?page=2"},
+		{http.MethodGet, "//users", http.StatusMovedPermanently, "/users"},
+		{http.MethodGet, "/a/../users/7", http.StatusMovedPermanently, "/users/7"},
+		{http.MethodGet, "/USERS/Ab", http.StatusMovedPermanently, "/users/Ab"},
+		{http.MethodGet, "/Users/", http.StatusMovedPermanently, "/users"},
+		{http.MethodHead, "/users/", http.StatusMovedPermanently, "/users"},
+		{http.MethodPost, "/users/", http.StatusPermanentRedirect, "/users"},
+		{http.MethodGet, "/Users/a%2Fb", http.StatusMovedPermanently, "/users/a%2Fb"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		assert.Equal(t, tt.code, rec.Code, tt.target)
+		assert.Equal(t, tt.location, rec.Header().Get("Location"), tt.target)
+	}
+
+	// Exact matches are never redirected
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_RoutingMatchesTransparently(t *testing.T) {
+	s := newNormalizationServer(RoutingConfig{
+		TrailingSlash:   PathMatch,
+		CleanPath:       PathMatch,
+		CaseInsensitive: PathMatch,
+	})
+
+	tests := map[string]string{
+		"/users/":             "users",
+		"/docs":               "docs",
+		"//users":             "users",
+		"/Users/MixedID":      "user MixedID",
+		"/USERS/":             "users",
+		"/./users/../users/1": "user 1",
+	}
+	for target, want := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		assert.Equal(t, want, rec.Body.String(), target)
+	}
+}
+
+func TestServer_RoutingMatchServesCanonicalPath(t *testing.T) {
+	s := New()
+	s.SetRouting(RoutingConfig{CaseInsensitive: PathMatch, CleanPath: PathMatch})
+	s.UseIf("/admin", func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) *Response { return c.String(http.StatusForbidden, "denied") }
+	})
+	s.GET("/admin/users/:id", func(c *Context) *Response { return c.String(http.StatusOK, "user "+c.Param("id")) })
+	s.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path + " " + r.URL.EscapedPath()))
+	}))
+
+	for _, target := range []string{"/admin/users/1", "/ADMIN/users/1", "//admin/users/1", "/x/../admin/users/1", "/Admin/users/a%2Fb"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code, target)
+	}
+
+	tests := map[string]string{
+		"/STATIC/css/app.css":  "/css/app.css /css/app.css",
+		"//static/css/app.css": "/css/app.css /css/app.css",
+		"/Static/a%2Fb":        "/a/b /a%2Fb",
+	}
+	for target, want := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		assert.Equal(t, want, rec.Body.String(), target)
+	}
+}
+
+func TestServer_RoutingMethodNotAllowedOnNormalizedPath(t *testing.T) {
+	s := newNormalizationServer(RoutingConfig{
+		TrailingSlash:   PathMatch,
+		CleanPath:       PathMatch,
+		CaseInsensitive: PathMatch,
+	})
+
+	for _, target := range []string{"/users/7/", "//users/7", "/USERS/7"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, target, nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, target)
+		assert.Equal(t, "GET, HEAD, OPTIONS", rec.Header().Get("Allow"), target)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/Docs", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+}
+
+func TestServer_RoutingRedirectWinsOverMatch(t *testing.T) {
+	s := newNormalizationServer(RoutingConfig{TrailingSlash: PathRedirect, CaseInsensitive: PathMatch})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/Users/", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/users", rec.Header().Get("Location"))
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/Users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_EncodedSlashStaysInParam(t *testing.T) {
+	s := New()
+	s.GET("/files/:name", func(c *Context) *Response { return c.String(http.StatusOK, "file "+c.Param("name")) })
+	s.GET("/files/:name/:file", func(c *Context) *Response { return c.String(http.StatusOK, "nested") })
+	s.GET("/raw/*path", func(c *Context) *Response { return c.String(http.StatusOK, "raw "+c.Param("path")) })
+	s.GET("/users/:id", func(c *Context) *Response { return c.String(http.StatusOK, "user "+c.Param("id")) })
+	s.GET("/docs/:dir/:name<alpha>", func(c *Context) *Response {
+		return c.String(http.StatusOK, "doc "+c.Param("dir")+" "+c.Param("name"))
+	})
+
+	tests := map[string]string{
+		"/%75sers/1":        "user 1",
+		"/docs/a%2Fb/%41bc": "doc a/b Abc",
+		"/%64ocs/x/%41%62c": "doc x Abc",
+		"/files/a%2Fb.txt":  "file a/b.txt",
+		"/files/a%20b.txt":  "file a b.txt",
+		"/files/dir/b.txt":  "nested",
+		"/raw/x%2Fy/z":      "raw x/y/z",
+		"/files/100%25%2F1": "file 100%/1",
+	}
+	for target, want := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		assert.Equal(t, want, rec.Body.String(), target)
+	}
+
+	for _, target := range []string{"/raw%2Fx", "/docs/a/b%31c", "/docs/a%2Fb/c%2Fd"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"time"
+)
+
+// ClientCertKey is the Context key under which the verified client
+// certificate of a mutual TLS connection is stored as a *ClientCert.
+const ClientCertKey = "client_cert"
+
+// ClientCert describes a verified TLS client certificate.
+type ClientCert struct {
+	Subject     string    `json:"subject"`     // Distinguished name, e.g. "CN=billing,O=Example"
+	CommonName  string    `json:"common_name"` // Subject common name
+	Issuer      string    `json:"issuer"`      // Issuer distinguished name
+	DNSNames    []string  `json:"dns_names,omitempty"`
+	Emails      []string  `json:"emails,omitempty"`
+	IPAddresses []string  `json:"ip_addresses,omitempty"`
+	URIs        []string  `json:"uris,omitempty"`
+	SPIFFEID    string    `json:"spiffe_id,omitempty"` // First spiffe:// URI SAN, e.g. "spiffe://example.org/ns/prod/sa/billing"
+	Fingerprint string    `json:"fingerprint"`         // Hex SHA-256 of the DER encoded certificate
+	Serial      string    `json:"serial"`
+	NotAfter    time.Time `json:"not_after"`
+
+	// Certificate is the parsed leaf certificate.
+	Certificate *x509.Certificate `json:"-"`
+}
+
+// NewClientCert extracts the identity of cert.
+func NewClientCert(cert *x509.Certificate) *ClientCert {
+	sum := sha256.Sum256(cert.Raw)
+	cc := &ClientCert{
+		Subject:     cert.Subject.String(),
+		CommonName:  cert.Subject.CommonName,
+		Issuer:      cert.Issuer.String(),
+		DNSNames:    cert.DNSNames,
+		Emails:      cert.EmailAddresses,
+		Fingerprint: hex.EncodeToString(sum[:]),
+		Serial:      cert.SerialNumber.String(),
+		NotAfter:    cert.NotAfter,
+		Certificate: cert,
+	}
+	for _, ip := range cert.IPAddresses {
+		cc.IPAddresses = append(cc.IPAddresses, ip.String())
+	}
+	for _, u := range cert.URIs {
+		cc.URIs = append(cc.URIs, u.String())
+		if u.Scheme == "spiffe" && cc.SPIFFEID == "" {
+			cc.SPIFFEID = u.String()
+		}
+	}
+	return cc
+}
+
+// ClientCert returns the verified client certificate of the request, or
+// nil if the client did not present one or it was not verified.
+// Example:
+//
+//	if cert := c.ClientCert(); cert != nil {
+//		log.Printf("called by %s", cert.SPIFFEID)
+//	}
+func (c *Context) ClientCert() *ClientCert {
+	cert, _ := c.Get(ClientCertKey).(*ClientCert)
+	return cert
+}
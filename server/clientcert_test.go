@@ -0,0 +1,50 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientCert(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://example.org/ns/prod/sa/billing")
+	other, _ := url.Parse("https://billing.example.org")
+	cert := &x509.Certificate{
+		Raw:            []byte("der bytes"),
+		SerialNumber:   big.NewInt(42),
+		Subject:        pkix.Name{CommonName: "billing", Organization: []string{"Example"}},
+		Issuer:         pkix.Name{CommonName: "Example CA"},
+		NotAfter:       time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		DNSNames:       []string{"billing.prod.svc"},
+		EmailAddresses: []string{"billing@example.org"},
+		IPAddresses:    []net.IP{net.IPv4(10, 0, 0, 7)},
+		URIs:           []*url.URL{other, spiffe},
+	}
+
+	cc := NewClientCert(cert)
+	sum := sha256.Sum256([]byte("der bytes"))
+	assert.Equal(t, "CN=billing,O=Example", cc.Subject)
+	assert.Equal(t, "billing", cc.CommonName)
+	assert.Equal(t, "CN=Example CA", cc.Issuer)
+	assert.Equal(t, []string{"billing.prod.svc"}, cc.DNSNames)
+	assert.Equal(t, []string{"billing@example.org"}, cc.Emails)
+	assert.Equal(t, []string{"10.0.0.7"}, cc.IPAddresses)
+	assert.Equal(t, []string{"https://billing.example.org", "spiffe://example.org/ns/prod/sa/billing"}, cc.URIs)
+	assert.Equal(t, "spiffe://example.org/ns/prod/sa/billing", cc.SPIFFEID)
+	assert.Equal(t, hex.EncodeToString(sum[:]), cc.Fingerprint)
+	assert.Equal(t, "42", cc.Serial)
+	assert.Same(t, cert, cc.Certificate)
+
+	c := &Context{}
+	assert.Nil(t, c.ClientCert())
+	c.Set(ClientCertKey, cc)
+	assert.Same(t, cc, c.ClientCert())
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// constraint restricts the values a path parameter accepts.
+// It is compiled once when the route is registered.
+type constraint struct {
+	source string            // text between '<' and '>' as written in the pattern
+	match  func(string) bool // reports whether a path segment satisfies the constraint
+}
+
+// String returns the constraint source, or "" for a nil constraint.
+func (c *constraint) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.source
+}
+
+// builtinConstraints maps the named constraints usable in route patterns,
+// e.g. "/users/:id<int>", to their matchers. Anything else between the
+// angle brackets is compiled as a regular expression.
+var builtinConstraints = map[string]func(string) bool{
+	"int":   isInt,
+	"uint":  isDigits,
+	"alpha": isAlpha,
+	"alnum": isAlnum,
+	"uuid":  isUUID,
+}
+
+// compileConstraint turns the source of a parameter constraint into a
+// matcher. Regular expressions are anchored so they must match the whole
+// segment.
+func compileConstraint(source string) (*constraint, error) {
+	if source == "" {
+		return nil, fmt.Errorf("empty constraint")
+	}
+	if fn, ok := builtinConstraints[source]; ok {
+		return &constraint{source: source, match: fn}, nil
+	}
+	re, err := regexp.Compile("^(?:" + source + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint <%s>: %w", source, err)
+	}
+	return &constraint{source: source, match: re.MatchString}, nil
+}
+
+// isInt reports whether s is a base-10 integer with an optional sign.
+func isInt(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return isDigits(s)
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlpha reports whether s is a non-empty run of ASCII letters.
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlnum reports whether s is a non-empty run of ASCII letters and digits.
+func isAlnum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether s is a canonical 8-4-4-4-12 hex UUID.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isHex(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
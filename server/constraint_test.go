@@ -0,0 +1,105 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileConstraint(t *testing.T) {
+	tests := []struct {
+		source string
+		accept []string
+		reject []string
+	}{
+		{"int", []string{"42", "-7", "+3"}, []string{"", "-", "4a", "1.5"}},
+		{"uint", []string{"0", "123"}, []string{"-1", "x"}},
+		{"alpha", []string{"abc", "ABC"}, []string{"ab1", ""}},
+		{"alnum", []string{"ab12"}, []string{"ab-12"}},
+		{"uuid", []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400z"}},
+		{"[a-z0-9-]+", []string{"hello-world-1"}, []string{"Hello", "a_b"}},
+		{"v[0-9]", []string{"v1"}, []string{"v10", "xv1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			c, err := compileConstraint(tt.source)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.source, c.String())
+			for _, s := range tt.accept {
+				assert.True(t, c.match(s), "expected %q to match", s)
+			}
+			for _, s := range tt.reject {
+				assert.False(t, c.match(s), "expected %q not to match", s)
+			}
+		})
+	}
+
+	_, err := compileConstraint("[a-z")
+	assert.Error(t, err)
+	_, err = compileConstraint("")
+	assert.Error(t, err)
+}
+
+func TestRouter_ConstrainedParams(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/users/:id<int>", namedHandler("by-id"))
+	router.Handle("GET", "/users/:name", namedHandler("by-name"))
+	router.Handle("GET", "/posts/:slug<[a-z0-9-]+>", namedHandler("post"))
+	router.Handle("GET", "/v/:uuid<uuid>/info", namedHandler("uuid"))
+
+	h, params := router.FindHandler("GET", "/users/42")
+	assert.Equal(t, "by-id", h(nil).Message)
+	assert.Equal(t, map[string]string{"id": "42"}, params)
+
+	h, params = router.FindHandler("GET", "/users/alice")
+	assert.Equal(t, "by-name", h(nil).Message)
+	assert.Equal(t, map[string]string{"name": "alice"}, params)
+
+	h, _ = router.FindHandler("GET", "/posts/hello-world")
+	assert.Equal(t, "post", h(nil).Message)
+	h, _ = router.FindHandler("GET", "/posts/Hello_World")
+	assert.Nil(t, h)
+
+	h, params = router.FindHandler("GET", "/v/123e4567-e89b-12d3-a456-426614174000/info")
+	assert.Equal(t, "uuid", h(nil).Message)
+	assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", params["uuid"])
+	h, _ = router.FindHandler("GET", "/v/not-a-uuid/info")
+	assert.Nil(t, h)
+}
+
+func TestRouter_ConstrainedParamTriedBeforeUnconstrained(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/items/:any", namedHandler("any"))
+	router.Handle("GET", "/items/:n<int>", namedHandler("int"))
+
+	h, _ := router.FindHandler("GET", "/items/5")
+	assert.Equal(t, "int", h(nil).Message)
+	h, _ = router.FindHandler("GET", "/items/five")
+	assert.Equal(t, "any", h(nil).Message)
+}
+
+func TestRouter_InvalidConstraintPanics(t *testing.T) {
+	patterns := []string{
+		"/users/:id<[a-z>",
+		"/users/:id<int",
+		"/users/:id<>",
+		"/users/:id<int>x",
+	}
+	for _, p := range patterns {
+		router := NewRouter()
+		assert.Panics(t, func() { router.Handle("GET", p, namedHandler("x")) }, p)
+	}
+}
+
+func TestRouter_RoutesReportsConstraints(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/users/:id<int>", namedHandler("x"))
+	router.Handle("POST", "/users", namedHandler("y"))
+
+	handler := "github.com/ascendingheavens/falcon/server.namedHandler.func1"
+	assert.Equal(t, []RouteInfo{
+		{Method: "GET", Path: "/users/:id<int>", Handler: handler, Constraints: map[string]string{"id": "int"}},
+		{Method: "POST", Path: "/users", Handler: handler},
+	}, router.Routes())
+}
@@ -105,6 +105,9 @@ func (c *Context) ShouldBindMultipart(dest any) error {
 
 // Param returns the value of a path parameter by name.
 // Example: /users/:id -> c.Param("id") returns "123"
+// Catch-all parameters hold the rest of the path without a leading slash:
+// /static/*filepath -> c.Param("filepath") returns "css/app.css",
+// and an unnamed /files/* is read with c.Param("*").
 func (c *Context) Param(name string) string {
 	if c.Params == nil {
 		return ""
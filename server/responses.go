@@ -1,10 +1,21 @@
 package server
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
 // String writes plain text and returns a Response
@@ -81,25 +92,238 @@ func (c *Context) Redirect(code int, location string) *Response {
 	}
 }
 
-// File serves a file from disk with proper Content-Type.
-// If file doesn't exist or can't be read, returns a 404/500 JSON response.
+// File streams a file from disk. The Content-Type comes from the file
+// extension (sniffed from the content only when the extension is unknown),
+// and Range, If-Range, If-Modified-Since and If-None-Match requests are
+// answered through http.ServeContent using the Last-Modified and ETag
+// headers it sets. A missing file or a directory gives a 404 JSON
+// response, a permission problem 403 and any other error 500.
+// Example: return c.File("./reports/2024.pdf")
 func (c *Context) File(filePath string) *Response {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return c.FileError(err)
+	}
+	defer f.Close()
+	return c.ServeFile(f, filepath.Base(filePath), nil)
+}
+
+// FileFS streams the file name from fsys like File, e.g. from an embed.FS.
+// Example: return c.FileFS(assets, "static/logo.svg")
+func (c *Context) FileFS(fsys fs.FS, name string) *Response {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return c.FileError(err)
+	}
+	defer f.Close()
+	return c.ServeFile(f, path.Base(name), nil)
+}
+
+// Attachment streams a file from disk like File and asks the browser to
+// download it as filename (the file's own name when empty). Non-ASCII
+// names are encoded as described in RFC 6266.
+// Example: return c.Attachment("./exports/42.csv", "Report März.csv")
+func (c *Context) Attachment(filePath, filename string) *Response {
+	return c.fileWithDisposition("attachment", filePath, filename)
+}
+
+// Inline streams a file from disk like File and asks the browser to
+// display it, suggesting filename (the file's own name when empty) for
+// saving it.
+// Example: return c.Inline("./invoices/42.pdf", "invoice-42.pdf")
+func (c *Context) Inline(filePath, filename string) *Response {
+	return c.fileWithDisposition("inline", filePath, filename)
+}
+
+func (c *Context) fileWithDisposition(disposition, filePath, filename string) *Response {
+	if filename == "" {
+		filename = filepath.Base(filePath)
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return c.FileError(err)
+	}
+	defer f.Close()
+	header := http.Header{"Content-Disposition": {ContentDisposition(disposition, filename)}}
+	return c.ServeFile(f, filepath.Base(filePath), header)
+}
+
+// ContentDisposition formats a Content-Disposition header value as
+// described in RFC 6266: an ASCII filename parameter for every client,
+// plus a UTF-8 filename* parameter when the name is not plain ASCII.
+// Example: ContentDisposition("attachment", "résumé.pdf") returns
+//
+//	attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf
+func ContentDisposition(disposition, filename string) string {
+	var fallback strings.Builder
+	plain := true
+	for _, r := range filename {
+		switch {
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			fallback.WriteByte('_')
+			plain = false
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	v := disposition + `; filename="` + fallback.String() + `"`
+	if !plain {
+		v += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return v
+}
+
+// encodeRFC5987 percent-encodes s for an ext-value, leaving only the
+// attr-char set of RFC 5987 unescaped.
+func encodeRFC5987(s string) string {
+	const attrChars = "!#$&+-.^_`|~"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') || strings.IndexByte(attrChars, ch) >= 0 {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
+
+// ServeFile streams the open file f like File. name gives the MIME type
+// and the name passed to http.ServeContent, so a precompressed "app.js.gz"
+// can be served as "app.js". header is added to the response only once f
+// is known to be readable, so error responses never carry it; an ETag in
+// header replaces the one derived from the file.
+func (c *Context) ServeFile(f fs.File, name string, header http.Header) *Response {
 	if c.Handled {
 		return &Response{Success: false, Message: "Response already handled", Code: 500}
 	}
 
-	data, err := os.ReadFile(filePath)
+	info, err := f.Stat()
 	if err != nil {
-		c.writeErrorResponse(404, "File not found", err)
-		return &Response{Success: false, Message: "File not found", Code: 404}
+		return c.FileError(err)
+	}
+	if info.IsDir() {
+		return c.FileError(fs.ErrNotExist)
 	}
 
-	contentType := http.DetectContentType(data)
-	c.writeResponse(200, contentType, data)
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return c.FileError(err)
+		}
+		content = bytes.NewReader(data)
+	}
 
-	return &Response{
-		Success: true,
-		Message: fmt.Sprintf("Served file: %s", filePath),
-		Code:    200,
+	h := c.Writer.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+	for k, v := range header {
+		h[k] = v
+	}
+	if h.Get("ETag") == "" {
+		if tag, err := FileETag(info, content); err == nil {
+			h.Set("ETag", tag)
+		}
+	}
+
+	r := c.Request
+	if r == nil {
+		r = &http.Request{Method: http.MethodGet, Header: http.Header{}, URL: &url.URL{Path: "/" + name}}
+	}
+	rec := &StatusRecorder{ResponseWriter: c.Writer}
+	http.ServeContent(rec, r, name, info.ModTime(), content)
+	c.Handled = true
+
+	code := rec.Status()
+	if code >= http.StatusBadRequest {
+		return &Response{Success: false, Message: http.StatusText(code), Code: code}
+	}
+	return &Response{Success: true, Message: fmt.Sprintf("Served file: %s", name), Code: code}
+}
+
+// FileETag derives a strong ETag from the size and modification time of a
+// file, or from a hash of its content for files without one (embed.FS).
+// content is rewound after hashing.
+func FileETag(info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if mod := info.ModTime(); !mod.IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, mod.UnixNano(), info.Size()), nil
+	}
+	sum := sha256.New()
+	if _, err := io.Copy(sum, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`, nil
+}
+
+// FileError writes the JSON error response for a failure to open or read
+// a file: 404 for a missing file, 403 for a permission problem and 500
+// otherwise. The error itself is not sent, as it names files on the
+// server.
+func (c *Context) FileError(err error) *Response {
+	code, message := http.StatusInternalServerError, "Failed to read file"
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code, message = http.StatusNotFound, "File not found"
+	case errors.Is(err, fs.ErrPermission):
+		code, message = http.StatusForbidden, "File not accessible"
+	}
+	return c.ErrorJSON(message, nil, code)
+}
+
+// StatusRecorder remembers the status code written through it, so the
+// outcome of http.ServeContent or a mounted http.Handler can be reported
+// back as a Response.
+type StatusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+// WriteHeader records the status code and forwards it.
+func (w *StatusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write marks the response as 200 OK if no status was written yet.
+func (w *StatusRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush forwards to the underlying writer when it supports flushing,
+// so streaming handlers keep working.
+func (w *StatusRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.code == 0 {
+			w.code = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *StatusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the recorded status, defaulting to 200 like net/http.
+func (w *StatusRecorder) Status() int {
+	if w.code == 0 {
+		return http.StatusOK
 	}
+	return w.code
 }
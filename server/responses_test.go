@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -93,9 +95,118 @@ func TestResponseHelpers(t *testing.T) {
 		assert.Equal(t, 404, rec.Code)
 		assert.True(t, c.Handled)
 		assert.Equal(t, "File not found", resp.Message)
+		assert.NotContains(t, rec.Body.String(), "nonexistent", "file system errors are not sent")
 	})
 }
 
+func TestFile_Streaming(t *testing.T) {
+	dir := t.TempDir()
+	cssPath := filepath.Join(dir, "app.css")
+	assert.NoError(t, os.WriteFile(cssPath, []byte("body{color:red}"), 0644))
+
+	serve := func(fn func(c *Context) *Response, header map[string]string) (*httptest.ResponseRecorder, *Response) {
+		req := httptest.NewRequest(http.MethodGet, "/file", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		resp := fn(&Context{Writer: rec, Request: req})
+		return rec, resp
+	}
+	file := func(c *Context) *Response { return c.File(cssPath) }
+
+	t.Run("content type from extension", func(t *testing.T) {
+		rec, resp := serve(file, nil)
+		assert.Equal(t, 200, rec.Code)
+		assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "15", rec.Header().Get("Content-Length"))
+		assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+		assert.True(t, resp.Success)
+	})
+
+	t.Run("range and if-range", func(t *testing.T) {
+		first, _ := serve(file, nil)
+		etag := first.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+
+		rec, resp := serve(file, map[string]string{"Range": "bytes=5-9"})
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "color", rec.Body.String())
+		assert.Equal(t, http.StatusPartialContent, resp.Code)
+
+		rec, _ = serve(file, map[string]string{"Range": "bytes=5-9", "If-Range": etag})
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+
+		rec, _ = serve(file, map[string]string{"Range": "bytes=5-9", "If-Range": `"stale"`})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "body{color:red}", rec.Body.String())
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		first, _ := serve(file, nil)
+		rec, _ := serve(file, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		rec, _ = serve(file, map[string]string{"If-Modified-Since": first.Header().Get("Last-Modified")})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("directory is not found", func(t *testing.T) {
+		rec, resp := serve(func(c *Context) *Response { return c.File(dir) }, nil)
+		assert.Equal(t, 404, rec.Code)
+		assert.Equal(t, "File not found", resp.Message)
+	})
+
+	t.Run("permission denied is forbidden", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root ignores file permissions")
+		}
+		locked := filepath.Join(dir, "locked.txt")
+		assert.NoError(t, os.WriteFile(locked, []byte("x"), 0000))
+		rec, resp := serve(func(c *Context) *Response { return c.File(locked) }, nil)
+		assert.Equal(t, 403, rec.Code)
+		assert.Equal(t, 403, resp.Code)
+	})
+
+	t.Run("attachment and inline", func(t *testing.T) {
+		rec, _ := serve(func(c *Context) *Response { return c.Attachment(cssPath, "Résumé \"final\".css") }, nil)
+		assert.Equal(t, 200, rec.Code)
+		assert.Equal(t, `attachment; filename="R_sum_ \"final\".css"; filename*=UTF-8''R%C3%A9sum%C3%A9%20%22final%22.css`, rec.Header().Get("Content-Disposition"))
+
+		rec, _ = serve(func(c *Context) *Response { return c.Inline(cssPath, "") }, nil)
+		assert.Equal(t, `inline; filename="app.css"`, rec.Header().Get("Content-Disposition"))
+
+		// The headers sent with the error, not the live map edited afterwards
+		rec, _ = serve(func(c *Context) *Response { return c.Attachment(filepath.Join(dir, "missing"), "x.csv") }, nil)
+		assert.Equal(t, 404, rec.Code)
+		assert.Empty(t, rec.Result().Header.Get("Content-Disposition"))
+		assert.Equal(t, "application/json", rec.Result().Header.Get("Content-Type"))
+	})
+
+	t.Run("FileFS", func(t *testing.T) {
+		fsys := fstest.MapFS{"static/app.js": {Data: []byte("console.log(1)")}}
+		rec, _ := serve(func(c *Context) *Response { return c.FileFS(fsys, "static/app.js") }, nil)
+		assert.Equal(t, 200, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "javascript")
+		assert.Equal(t, "console.log(1)", rec.Body.String())
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+
+		rec, _ = serve(func(c *Context) *Response { return c.FileFS(fsys, "static/missing.js") }, nil)
+		assert.Equal(t, 404, rec.Code)
+	})
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := map[string]string{
+		"report.pdf":  `attachment; filename="report.pdf"`,
+		`a"b\c.txt`:   `attachment; filename="a\"b\\c.txt"`,
+		"日本.txt":      `attachment; filename="__.txt"; filename*=UTF-8''%E6%97%A5%E6%9C%AC.txt`,
+		"line\nbreak": `attachment; filename="line_break"; filename*=UTF-8''line%0Abreak`,
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, ContentDisposition("attachment", name), name)
+	}
+}
+
 func TestErrorJSON(t *testing.T) {
 	t.Run("Writes JSON response and sets handled", func(t *testing.T) {
 		rec := httptest.NewRecorder()
@@ -1,21 +1,189 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"runtime"
+	"slices"
 	"strings"
 )
 
+var (
+	// ErrInvalidRoute is returned by Router.TryHandle for malformed route patterns.
+	ErrInvalidRoute = errors.New("invalid route")
+
+	// ErrRouteConflict is returned by Router.TryHandle when a route would be
+	// shadowed by, or ambiguous with, one that is already registered.
+	ErrRouteConflict = errors.New("route conflict")
+)
+
 // NewRouter creates and returns a new Router instance.
 func NewRouter() *Router {
-	return &Router{routes: make([]route, 0)}
+	return &Router{
+		trees:  make(map[string]*node),
+		routes: make([]*Route, 0),
+		names:  make(map[string]*Route),
+	}
 }
 
 // Handle registers a new route with a specific HTTP method, path, and handler.
-func (r *Router) Handle(method, path string, handler HandlerFunc) {
-	r.routes = append(r.routes, route{
-		Method:  method,
-		Path:    path,
-		Handler: handler,
-	})
+// Parameters may carry a constraint, e.g. "/users/:id<int>" or
+// "/posts/:slug<[a-z0-9-]+>"; requests whose segment does not satisfy it
+// fall through to other routes. Constraints are compiled here.
+// Handle panics if the pattern is malformed or conflicts with an existing
+// route, so broken route tables fail at startup; use TryHandle to get
+// the error instead.
+// The returned Route can be named for reverse URL generation.
+func (r *Router) Handle(method, path string, handler HandlerFunc) *Route {
+	rt, err := r.TryHandle(method, path, handler)
+	if err != nil {
+		panic("falcon: " + err.Error())
+	}
+	return rt
+}
+
+// TryHandle registers a route like Handle but returns an error instead of
+// panicking. Errors wrap ErrInvalidRoute for malformed patterns and
+// ErrRouteConflict when the same method and path are already registered
+// or a parameter at the same position has a different name (e.g.
+// "/users/:id" vs "/users/:name"). A failed registration leaves every
+// previously registered route untouched.
+func (r *Router) TryHandle(method, path string, handler HandlerFunc) (*Route, error) {
+	segs, err := parsePattern(path)
+	if err != nil {
+		return nil, fmt.Errorf("route %s %s: %w", method, path, err)
+	}
+
+	root, ok := r.trees[method]
+	if !ok {
+		root = &node{}
+		r.trees[method] = root
+	}
+
+	leaf, err := root.insert(segs)
+	if err != nil {
+		return nil, fmt.Errorf("route %s %s: %w", method, path, err)
+	}
+	if leaf.route != nil {
+		return nil, fmt.Errorf("route %s %s: %w: already registered as %s", method, path, ErrRouteConflict, leaf.route.Path)
+	}
+
+	rt := &Route{
+		Method:      method,
+		Path:        path,
+		Handler:     handler,
+		HandlerName: HandlerName(handler),
+		segments:    segs,
+		router:      r,
+	}
+	leaf.route = rt
+
+	for _, seg := range segs {
+		if seg.constraint != nil {
+			if rt.Constraints == nil {
+				rt.Constraints = make(map[string]string)
+			}
+			rt.Constraints[seg.text] = seg.constraint.String()
+		}
+	}
+
+	r.routes = append(r.routes, rt)
+	return rt, nil
+}
+
+// Routes returns a description of every registered route in registration
+// order, including the constraints attached to its parameters.
+func (r *Router) Routes() []RouteInfo {
+	infos := make([]RouteInfo, 0, len(r.routes))
+	for _, rt := range r.routes {
+		infos = append(infos, rt.Info())
+	}
+	return infos
+}
+
+// IsStatic reports whether the route pattern has no parameters, so the
+// route matches exactly one path.
+func (rt *Route) IsStatic() bool {
+	for _, seg := range rt.segments {
+		if seg.kind != staticNode {
+			return false
+		}
+	}
+	return true
+}
+
+// Info describes the route for introspection. The router knows nothing
+// about hosts or middleware, so Host and Middlewares are left for the
+// caller to fill in.
+func (rt *Route) Info() RouteInfo {
+	return RouteInfo{
+		Method:      rt.Method,
+		Path:        rt.Path,
+		Name:        rt.name,
+		Handler:     rt.HandlerName,
+		Constraints: maps.Clone(rt.Constraints),
+	}
+}
+
+// HandlerName returns the fully qualified name of the function behind h,
+// e.g. "main.getUser", or an empty string for a nil handler.
+func HandlerName(h HandlerFunc) string {
+	if h == nil {
+		return ""
+	}
+	if fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// Lookup finds the handler registered for method and path, appending any
+// captured path parameters to ps. It returns nil if no route matches.
+// Lookup does not allocate for static routes; callers that reuse ps with
+// enough capacity avoid allocations for parameterized routes as well.
+func (r *Router) Lookup(method, path string, ps *Params) HandlerFunc {
+	if rt := r.LookupRoute(method, path, ps); rt != nil {
+		return rt.Handler
+	}
+	return nil
+}
+
+// LookupRoute is like Lookup but returns the matched Route itself, so
+// callers can dispatch to a handler they prepared for it.
+func (r *Router) LookupRoute(method, path string, ps *Params) *Route {
+	return r.lookupRoute(method, path, ps, 0)
+}
+
+// LookupRouteFold is like LookupRoute but compares the static parts of
+// route patterns ignoring ASCII case, so "/Users/42" finds "/users/:id".
+// Parameter values are captured as they appear in path.
+func (r *Router) LookupRouteFold(method, path string, ps *Params) *Route {
+	return r.lookupRoute(method, path, ps, matchFold)
+}
+
+// LookupRouteEscaped is like LookupRoute for an escaped path, such as
+// url.URL.RawPath. Static text and constraints are compared with the
+// unescaped path, so "/%75sers/42" finds "/users/:id", but parameter
+// values are captured escaped and an encoded slash stays inside one.
+// With fold set, static text is also compared ignoring ASCII case.
+func (r *Router) LookupRouteEscaped(method, path string, ps *Params, fold bool) *Route {
+	return r.lookupRoute(method, path, ps, escapedMode(fold))
+}
+
+func (r *Router) lookupRoute(method, path string, ps *Params, mode matchMode) *Route {
+	root, ok := r.trees[method]
+	if !ok {
+		return nil
+	}
+	mark := len(*ps)
+	if leaf := root.lookup(path, ps, mode); leaf != nil {
+		return leaf.route
+	}
+	*ps = (*ps)[:mark]
+	return nil
 }
 
 // FindHandler attempts to match an incoming request (method + path)
@@ -24,42 +192,168 @@ func (r *Router) Handle(method, path string, handler HandlerFunc) {
 // Returns the matching HandlerFunc and a map of extracted params.
 // If no match is found, it returns (nil, nil).
 func (r *Router) FindHandler(method, path string) (HandlerFunc, map[string]string) {
-	for _, rt := range r.routes {
-		// Skip if method doesn't match
-		if rt.Method != method {
+	var ps Params
+	handler := r.Lookup(method, path, &ps)
+	if handler == nil {
+		return nil, nil
+	}
+	return handler, ps.Map()
+}
+
+// AllowedMethods returns the sorted list of HTTP methods that have a route
+// matching path. An empty result means no route matches the path at all,
+// while a non-empty one for a request whose method is missing from it
+// means the server should answer 405 Method Not Allowed.
+func (r *Router) AllowedMethods(path string) []string {
+	return r.allowedMethods(path, 0)
+}
+
+// AllowedMethodsFold is like AllowedMethods but compares the static parts
+// of route patterns ignoring ASCII case, like LookupRouteFold.
+func (r *Router) AllowedMethodsFold(path string) []string {
+	return r.allowedMethods(path, matchFold)
+}
+
+// AllowedMethodsEscaped is like AllowedMethods for an escaped path,
+// matched like LookupRouteEscaped.
+func (r *Router) AllowedMethodsEscaped(path string, fold bool) []string {
+	return r.allowedMethods(path, escapedMode(fold))
+}
+
+func (r *Router) allowedMethods(path string, mode matchMode) []string {
+	var allowed []string
+	var ps Params
+	for method, root := range r.trees {
+		ps = ps[:0]
+		if root.lookup(path, &ps, mode) != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	slices.Sort(allowed)
+	return allowed
+}
+
+// URL builds the path of the route registered under name, filling in its
+// parameters from key/value pairs. Values are path-escaped; catch-all
+// values keep their slashes. It returns an error for an unknown route name
+// and for missing, unknown or constraint-violating parameters.
+// Example: r.URL("user.show", "id", "42") -> "/users/42"
+func (r *Router) URL(name string, pairs ...string) (string, error) {
+	rt, ok := r.names[name]
+	if !ok {
+		return "", fmt.Errorf("unknown route name %q", name)
+	}
+	return rt.URL(pairs...)
+}
+
+// NamedRoute returns the route registered under name, or nil.
+func (r *Router) NamedRoute(name string) *Route {
+	return r.names[name]
+}
+
+// Name attaches a name to the route so its URL can be generated with
+// Router.URL. Several routes may share a name (e.g. GET and POST on the
+// same path), but Name panics if the name is already used for another path.
+func (rt *Route) Name(name string) *Route {
+	if existing, ok := rt.router.names[name]; ok && existing.Path != rt.Path {
+		panic(fmt.Sprintf("falcon: route name %q already used for %s", name, existing.Path))
+	}
+	rt.name = name
+	rt.router.names[name] = rt
+	return rt
+}
+
+// URL builds the path of this route, filling in its parameters from
+// key/value pairs. See Router.URL.
+func (rt *Route) URL(pairs ...string) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("route %s: odd number of URL parameters", rt.Path)
+	}
+	values := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		if _, dup := values[pairs[i]]; dup {
+			return "", fmt.Errorf("route %s: duplicate URL parameter %q", rt.Path, pairs[i])
+		}
+		values[pairs[i]] = pairs[i+1]
+	}
+
+	var b strings.Builder
+	for _, seg := range rt.segments {
+		if seg.kind == staticNode {
+			b.WriteString(seg.text)
 			continue
 		}
 
-		// Split both route and incoming path into parts
-		params := make(map[string]string)
-		rtParts := strings.Split(rt.Path, "/")
-		pParts := strings.Split(path, "/")
+		v, ok := values[seg.text]
+		if !ok {
+			return "", fmt.Errorf("route %s: missing URL parameter %q", rt.Path, seg.text)
+		}
+		delete(values, seg.text)
 
-		// Length mismatch -> no match
-		if len(rtParts) != len(pParts) {
+		if seg.kind == catchAllNode {
+			parts := strings.Split(v, "/")
+			for i, part := range parts {
+				parts[i] = url.PathEscape(part)
+			}
+			b.WriteString(strings.Join(parts, "/"))
 			continue
 		}
 
-		// Check each segment
-		match := true
-		for i := range rtParts {
-			if strings.HasPrefix(rtParts[i], ":") {
-				// It's a path parameter, capture it
-				params[rtParts[i][1:]] = pParts[i]
-			} else if rtParts[i] != pParts[i] {
-				// Static segment mismatch -> route doesn't match
-				match = false
+		if v == "" {
+			return "", fmt.Errorf("route %s: empty URL parameter %q", rt.Path, seg.text)
+		}
+		if seg.constraint != nil && !seg.constraint.match(v) {
+			return "", fmt.Errorf("route %s: URL parameter %q does not satisfy <%s>", rt.Path, seg.text, seg.constraint)
+		}
+		b.WriteString(url.PathEscape(v))
+	}
+
+	if len(values) > 0 {
+		unknown := slices.Sorted(maps.Keys(values))
+		return "", fmt.Errorf("route %s: unknown URL parameters %q", rt.Path, unknown)
+	}
+	return b.String(), nil
+}
+
+// Expand fills the route pattern with the parameter values in ps, as they
+// are and without checking constraints, e.g. to rebuild the canonical form
+// of a request path that matched the route. When ps holds a name twice,
+// the last value wins.
+func (rt *Route) Expand(ps Params) string {
+	var b strings.Builder
+	for _, seg := range rt.segments {
+		if seg.kind == staticNode {
+			b.WriteString(seg.text)
+			continue
+		}
+		for i := len(ps) - 1; i >= 0; i-- {
+			if ps[i].Key == seg.text {
+				b.WriteString(ps[i].Value)
 				break
 			}
 		}
+	}
+	return b.String()
+}
 
-		if match {
-			return rt.Handler, params
+// Get returns the value of the first parameter with the given name,
+// or an empty string if it was not captured.
+func (ps Params) Get(name string) string {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value
 		}
 	}
+	return ""
+}
 
-	// No matching route found
-	return nil, nil
+// Map copies the parameters into a map keyed by parameter name.
+func (ps Params) Map() map[string]string {
+	m := make(map[string]string, len(ps))
+	for _, p := range ps {
+		m[p.Key] = p.Value
+	}
+	return m
 }
 
 // Set stores a value in the Context under the specified key.
@@ -109,3 +109,81 @@ func TestContextSetGet(t *testing.T) {
 		assert.Equal(t, "value", c2.Values["key"])
 	})
 }
+
+func TestRouter_AllowedMethods(t *testing.T) {
+	router := NewRouter()
+	h := func(c *Context) *Response { return nil }
+	router.Handle("POST", "/users", h)
+	router.Handle("GET", "/users", h)
+	router.Handle("DELETE", "/users/:id", h)
+
+	assert.Equal(t, []string{"GET", "POST"}, router.AllowedMethods("/users"))
+	assert.Equal(t, []string{"DELETE"}, router.AllowedMethods("/users/1"))
+	assert.Empty(t, router.AllowedMethods("/missing"))
+}
+
+func TestRouter_URL(t *testing.T) {
+	router := NewRouter()
+	h := func(c *Context) *Response { return nil }
+	router.Handle("GET", "/users/:id", h).Name("user.show")
+	router.Handle("POST", "/users/:id", h).Name("user.show")
+	router.Handle("GET", "/users/:id<int>/posts/:slug", h).Name("user.post")
+	router.Handle("GET", "/static/*filepath", h).Name("static")
+	router.Handle("GET", "/about", h).Name("about")
+
+	tests := []struct {
+		name  string
+		route string
+		pairs []string
+		want  string
+		err   string
+	}{
+		{"static", "about", nil, "/about", ""},
+		{"param", "user.show", []string{"id", "42"}, "/users/42", ""},
+		{"escaped param", "user.show", []string{"id", "a/b c"}, "/users/a%2Fb%20c", ""},
+		{"catch-all keeps slashes", "static", []string{"filepath", "css/my app.css"}, "/static/css/my%20app.css", ""},
+		{"multiple params", "user.post", []string{"id", "1", "slug", "hello"}, "/users/1/posts/hello", ""},
+		{"unknown route", "nope", nil, "", "unknown route name"},
+		{"missing param", "user.show", nil, "", "missing URL parameter"},
+		{"unknown param", "user.show", []string{"id", "1", "extra", "x"}, "", "unknown URL parameters"},
+		{"odd pairs", "user.show", []string{"id"}, "", "odd number"},
+		{"duplicate param", "user.show", []string{"id", "1", "id", "2"}, "", "duplicate URL parameter"},
+		{"empty param", "user.show", []string{"id", ""}, "", "empty URL parameter"},
+		{"constraint violated", "user.post", []string{"id", "x", "slug", "s"}, "", "does not satisfy <int>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := router.URL(tt.route, tt.pairs...)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRoute_NameConflictPanics(t *testing.T) {
+	router := NewRouter()
+	h := func(c *Context) *Response { return nil }
+	router.Handle("GET", "/a", h).Name("x")
+	assert.Panics(t, func() {
+		router.Handle("GET", "/b", h).Name("x")
+	})
+	assert.Equal(t, "x", router.Routes()[0].Name)
+}
+
+func getUsers(c *Context) *Response { return nil }
+
+func TestRouter_RoutesIntrospection(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/users", getUsers).Name("users")
+	router.Handle("POST", "/users/:id", nil)
+
+	assert.Equal(t, []RouteInfo{
+		{Method: "GET", Path: "/users", Name: "users", Handler: "github.com/ascendingheavens/falcon/server.getUsers"},
+		{Method: "POST", Path: "/users/:id"},
+	}, router.Routes())
+}
@@ -1,19 +1,25 @@
 package server
 
 import (
+	"errors"
 	"html/template"
 	"net/http"
 	"sync"
+	"sync/atomic"
 )
 
+// urlBuilderFunc builds the URL of a named route from key/value pairs.
+type urlBuilderFunc func(name string, pairs ...string) (string, error)
+
 // TemplateRenderer manages HTML templates for rendering in Falcon.
 // It supports thread-safe access and optional development mode for live reloading.
 type TemplateRenderer struct {
-	templates *template.Template
-	funcs     template.FuncMap
-	mu        sync.RWMutex
-	pattern   string
-	devMode   bool
+	templates  *template.Template
+	funcs      template.FuncMap
+	mu         sync.RWMutex
+	pattern    string
+	devMode    bool
+	urlBuilder atomic.Pointer[urlBuilderFunc] // read while templates render
 }
 
 // NewTemplateRenderer initializes a TemplateRenderer that parses templates
@@ -30,14 +36,39 @@ func NewTemplateRenderer(pattern string, devMode bool, funcs template.FuncMap) *
 	return tr
 }
 
+// SetURLBuilder sets the function behind the built-in "url" template func,
+// typically a router's URL method, so templates can link to named routes:
+//
+//	<a href="{{ url "user.show" "id" .ID }}">profile</a>
+func (tr *TemplateRenderer) SetURLBuilder(fn func(name string, pairs ...string) (string, error)) {
+	if fn == nil {
+		tr.urlBuilder.Store(nil)
+		return
+	}
+	b := urlBuilderFunc(fn)
+	tr.urlBuilder.Store(&b)
+}
+
+// url is the built-in "url" template func. It fails the render if no
+// URL builder has been set or the route cannot be built.
+func (tr *TemplateRenderer) url(name string, pairs ...string) (string, error) {
+	b := tr.urlBuilder.Load()
+	if b == nil {
+		return "", errors.New("url: no URL builder set on TemplateRenderer")
+	}
+	return (*b)(name, pairs...)
+}
+
 // mustLoad parses all templates according to the pattern.
 // It panics if parsing fails, enforcing fail-fast behavior.
 // Called internally by NewTemplateRenderer and in dev mode.
+// The built-in "url" func is registered first, so user funcs may override it.
 func (tr *TemplateRenderer) mustLoad() {
 	tr.mu.Lock()
 	defer tr.mu.Unlock()
 
-	parsed, err := template.New("").Funcs(tr.funcs).ParseGlob(tr.pattern)
+	builtins := template.FuncMap{"url": tr.url}
+	parsed, err := template.New("").Funcs(builtins).Funcs(tr.funcs).ParseGlob(tr.pattern)
 	if err != nil {
 		panic("failed to parse templates: " + err.Error())
 	}
@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/ascendingheavens/falcon/server"
@@ -165,3 +166,46 @@ func TestTemplateRenderer_DevModeReload(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Contains(t, rec2.Body.String(), "Bye Rishi")
 }
+
+// 5. Built-in url func builds links to named routes
+func TestTemplateRenderer_URLFunc(t *testing.T) {
+	templatePath := createTempTemplate(t, `<a href="{{ url "user.show" "id" .ID }}">me</a>`)
+	tr := server.NewTemplateRenderer(templatePath, false, nil)
+
+	// Without a URL builder the render fails
+	err := tr.Render(httptest.NewRecorder(), "test.html", map[string]string{"ID": "7"})
+	assert.Error(t, err)
+
+	router := server.NewRouter()
+	router.Handle(http.MethodGet, "/users/:id", nil).Name("user.show")
+	tr.SetURLBuilder(router.URL)
+
+	rec := httptest.NewRecorder()
+	err = tr.Render(rec, "test.html", map[string]string{"ID": "a b"})
+	assert.NoError(t, err)
+	assert.Equal(t, `<a href="/users/a%20b">me</a>`, rec.Body.String())
+}
+
+// 6. The URL builder can be replaced while templates render
+func TestTemplateRenderer_SetURLBuilderWhileRendering(t *testing.T) {
+	templatePath := createTempTemplate(t, `{{ url "home" }}`)
+	tr := server.NewTemplateRenderer(templatePath, false, nil)
+	router := server.NewRouter()
+	router.Handle(http.MethodGet, "/", nil).Name("home")
+	tr.SetURLBuilder(router.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, tr.Render(httptest.NewRecorder(), "test.html", nil))
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		tr.SetURLBuilder(router.URL)
+	}
+	wg.Wait()
+}
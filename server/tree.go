@@ -0,0 +1,382 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// nodeKind identifies how a node in the routing tree matches request paths.
+type nodeKind uint8
+
+const (
+	staticNode   nodeKind = iota // matches its literal prefix
+	paramNode                    // matches a single ":name" path segment
+	catchAllNode                 // matches the rest of the path ("*name")
+)
+
+// node is a single vertex of the compressed prefix (radix) tree used by Router.
+// Static children that share a common prefix are merged, so a lookup only
+// compares the bytes that actually differ between registered routes.
+type node struct {
+	kind       nodeKind
+	prefix     string      // literal text matched by a static node
+	name       string      // parameter name for a param or catch-all node
+	constraint *constraint // optional restriction on the values a param node accepts
+	indices    string      // first byte of every static child, aligned with children
+	children   []*node     // static children
+	params     []*node     // parameter children, tried after the static ones
+	wildcard   *node       // catch-all child, tried last
+	route      *Route      // set when a route terminates at this node
+}
+
+// segment is one piece of a parsed route pattern.
+type segment struct {
+	kind       nodeKind
+	text       string      // literal text for static segments, name for parameters
+	constraint *constraint // optional constraint for ":name<...>" parameters
+}
+
+// parsePattern splits a route pattern into static text and parameters.
+// A ':' or '*' only starts a parameter at the beginning of a segment.
+// A ':' parameter extends until the next '/' and may carry a constraint in
+// angle brackets (":id<int>", ":slug<[a-z0-9-]+>"), while a '*' catch-all
+// must be the last segment and captures the rest of the path.
+// A bare '*' is stored under the name "*".
+// Errors wrap ErrInvalidRoute.
+func parsePattern(pattern string) ([]segment, error) {
+	segs, err := splitPattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRoute, err)
+	}
+	return segs, nil
+}
+
+// splitPattern does the actual work of parsePattern.
+func splitPattern(pattern string) ([]segment, error) {
+	var segs []segment
+	for pattern != "" {
+		i := paramStart(pattern)
+		if i < 0 {
+			return append(segs, segment{kind: staticNode, text: pattern}), nil
+		}
+		if i > 0 {
+			segs = append(segs, segment{kind: staticNode, text: pattern[:i]})
+		}
+
+		if pattern[i] == '*' {
+			name := pattern[i+1:]
+			if strings.IndexByte(name, '/') >= 0 {
+				return nil, fmt.Errorf("catch-all parameter must be the last segment")
+			}
+			if name == "" {
+				name = "*"
+			}
+			return append(segs, segment{kind: catchAllNode, text: name}), nil
+		}
+
+		end := i + 1
+		for end < len(pattern) && pattern[end] != '/' && pattern[end] != '<' {
+			end++
+		}
+		seg := segment{kind: paramNode, text: pattern[i+1 : end]}
+		if seg.text == "" {
+			return nil, fmt.Errorf("missing parameter name")
+		}
+
+		if end < len(pattern) && pattern[end] == '<' {
+			closing := constraintEnd(pattern, end)
+			if closing < 0 {
+				return nil, fmt.Errorf("unterminated constraint for parameter %q", seg.text)
+			}
+			c, err := compileConstraint(pattern[end+1 : closing])
+			if err != nil {
+				return nil, fmt.Errorf("parameter %q: %w", seg.text, err)
+			}
+			seg.constraint = c
+			end = closing + 1
+			if end < len(pattern) && pattern[end] != '/' {
+				return nil, fmt.Errorf("unexpected %q after constraint for parameter %q", pattern[end:], seg.text)
+			}
+		}
+
+		segs = append(segs, seg)
+		pattern = pattern[end:]
+	}
+	return segs, nil
+}
+
+// constraintEnd returns the index of the '>' that closes the '<' at start,
+// allowing nested angle brackets inside regular expressions, or -1.
+func constraintEnd(pattern string, start int) int {
+	depth := 0
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// insert adds the parsed route pattern to the tree rooted at n and returns
+// the node that terminates it.
+func (n *node) insert(segs []segment) (*node, error) {
+	for _, seg := range segs {
+		switch seg.kind {
+		case staticNode:
+			n = n.insertStatic(seg.text)
+		case paramNode:
+			child, err := n.paramChild(seg.text, seg.constraint)
+			if err != nil {
+				return nil, err
+			}
+			n = child
+		case catchAllNode:
+			if n.wildcard == nil {
+				n.wildcard = &node{kind: catchAllNode, name: seg.text}
+			} else if n.wildcard.name != seg.text {
+				return nil, fmt.Errorf("%w: catch-all parameter *%s conflicts with existing *%s", ErrRouteConflict, seg.text, n.wildcard.name)
+			}
+			n = n.wildcard
+		}
+	}
+	return n, nil
+}
+
+// insertStatic walks (and, where needed, splits) the static children of n
+// so that s is matched literally, returning the node where s ends.
+func (n *node) insertStatic(s string) *node {
+	for s != "" {
+		i := strings.IndexByte(n.indices, s[0])
+		if i < 0 {
+			child := &node{kind: staticNode, prefix: s}
+			n.indices += string(s[0])
+			n.children = append(n.children, child)
+			return child
+		}
+
+		child := n.children[i]
+		l := commonPrefix(child.prefix, s)
+		if l < len(child.prefix) {
+			// Split the child so the shared part becomes its own node.
+			split := &node{
+				kind:     staticNode,
+				prefix:   child.prefix[:l],
+				indices:  string(child.prefix[l]),
+				children: []*node{child},
+			}
+			child.prefix = child.prefix[l:]
+			n.children[i] = split
+			child = split
+		}
+
+		n = child
+		s = s[l:]
+	}
+	return n
+}
+
+// paramChild returns the parameter child of n with the given name and
+// constraint, creating it if it does not exist yet. Constrained parameters
+// are kept ahead of unconstrained ones so the more specific match is tried
+// first; otherwise registration order is preserved. Two parameters with
+// the same constraint but different names at the same position would be
+// ambiguous, so that is reported as a conflict.
+func (n *node) paramChild(name string, c *constraint) (*node, error) {
+	for _, p := range n.params {
+		if p.constraint.String() != c.String() {
+			continue
+		}
+		if p.name != name {
+			return nil, fmt.Errorf("%w: parameter :%s conflicts with existing :%s at the same position", ErrRouteConflict, name, p.name)
+		}
+		return p, nil
+	}
+
+	p := &node{kind: paramNode, name: name, constraint: c}
+	at := len(n.params)
+	if c != nil {
+		for i, existing := range n.params {
+			if existing.constraint == nil {
+				at = i
+				break
+			}
+		}
+	}
+	n.params = slices.Insert(n.params, at, p)
+	return p, nil
+}
+
+// matchMode controls how lookup compares a request path with the tree.
+type matchMode uint8
+
+const (
+	// matchFold compares static text ignoring ASCII case.
+	matchFold matchMode = 1 << iota
+	// matchEscaped treats the path as escaped, like url.URL.RawPath:
+	// static text and constraints are compared with the unescaped path,
+	// while parameter values are captured as they appear in it.
+	matchEscaped
+)
+
+// escapedMode returns the mode for looking up an escaped path.
+func escapedMode(fold bool) matchMode {
+	if fold {
+		return matchEscaped | matchFold
+	}
+	return matchEscaped
+}
+
+// lookup matches path (the input left after n's own prefix) against the
+// subtree rooted at n. Static children are always tried first, then
+// parameters, then the catch-all. The search backtracks when a branch
+// dead-ends, so priority does not depend on registration order.
+// Captured parameters are appended to ps.
+func (n *node) lookup(path string, ps *Params, mode matchMode) *node {
+	if path == "" {
+		if n.route != nil {
+			return n
+		}
+	} else if leaf := n.lookupChildren(path, ps, mode); leaf != nil {
+		return leaf
+	}
+
+	if n.wildcard != nil && n.wildcard.route != nil {
+		*ps = append(*ps, Param{Key: n.wildcard.name, Value: path})
+		return n.wildcard
+	}
+
+	return nil
+}
+
+// lookupChildren tries the static and parameter children of n against a
+// non-empty path.
+func (n *node) lookupChildren(path string, ps *Params, mode matchMode) *node {
+	if mode == 0 {
+		if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+			child := n.children[i]
+			if strings.HasPrefix(path, child.prefix) {
+				if leaf := child.lookup(path[len(child.prefix):], ps, mode); leaf != nil {
+					return leaf
+				}
+			}
+		}
+	} else {
+		for _, child := range n.children {
+			if end := matchPrefix(path, child.prefix, mode); end >= 0 {
+				if leaf := child.lookup(path[end:], ps, mode); leaf != nil {
+					return leaf
+				}
+			}
+		}
+	}
+
+	if len(n.params) > 0 {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			value := path[:end]
+			checked := value
+			if mode&matchEscaped != 0 && strings.IndexByte(value, '%') >= 0 {
+				if v, err := url.PathUnescape(value); err == nil {
+					checked = v
+				}
+			}
+			for _, p := range n.params {
+				if p.constraint != nil && !p.constraint.match(checked) {
+					continue
+				}
+				mark := len(*ps)
+				*ps = append(*ps, Param{Key: p.name, Value: value})
+				if leaf := p.lookup(path[end:], ps, mode); leaf != nil {
+					return leaf
+				}
+				*ps = (*ps)[:mark]
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchPrefix returns the length of the start of path that matches the
+// static text prefix under mode, or -1 if it does not. With matchEscaped,
+// escapes in path are decoded before comparing, except for an encoded
+// slash, which never matches a separator in prefix.
+func matchPrefix(path, prefix string, mode matchMode) int {
+	i := 0
+	for j := 0; j < len(prefix); j++ {
+		if i >= len(path) {
+			return -1
+		}
+		b, width := path[i], 1
+		if b == '%' && mode&matchEscaped != 0 && i+2 < len(path) {
+			if d, ok := unhex(path[i+1], path[i+2]); ok && d != '/' {
+				b, width = d, 3
+			}
+		}
+		if b != prefix[j] && (mode&matchFold == 0 || toLowerASCII(b) != toLowerASCII(prefix[j])) {
+			return -1
+		}
+		i += width
+	}
+	return i
+}
+
+// unhex decodes the two hex digits of an escape.
+func unhex(hi, lo byte) (byte, bool) {
+	h, ok1 := fromHex(hi)
+	l, ok2 := fromHex(lo)
+	return h<<4 | l, ok1 && ok2
+}
+
+func fromHex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// paramStart returns the index of the first ':' or '*' that begins a path
+// segment, or -1 if the pattern contains no parameters.
+func paramStart(pattern string) int {
+	for i := 0; i < len(pattern); i++ {
+		if (pattern[i] == ':' || pattern[i] == '*') && (i == 0 || pattern[i-1] == '/') {
+			return i
+		}
+	}
+	return -1
+}
+
+func toLowerASCII(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// commonPrefix returns the length of the longest common prefix of a and b.
+func commonPrefix(a, b string) int {
+	n := min(len(a), len(b))
+	i := 0
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func namedHandler(name string) HandlerFunc {
+	return func(c *Context) *Response {
+		return &Response{Success: true, Message: name, Code: 200}
+	}
+}
+
+func TestRouter_StaticBeatsParamRegardlessOfOrder(t *testing.T) {
+	orders := map[string][]string{
+		"param first":  {"/users/:id", "/users/new"},
+		"static first": {"/users/new", "/users/:id"},
+	}
+
+	for name, paths := range orders {
+		t.Run(name, func(t *testing.T) {
+			router := NewRouter()
+			for _, p := range paths {
+				router.Handle("GET", p, namedHandler(p))
+			}
+
+			h, params := router.FindHandler("GET", "/users/new")
+			assert.Equal(t, "/users/new", h(nil).Message)
+			assert.Empty(t, params)
+
+			h, params = router.FindHandler("GET", "/users/42")
+			assert.Equal(t, "/users/:id", h(nil).Message)
+			assert.Equal(t, map[string]string{"id": "42"}, params)
+		})
+	}
+}
+
+func TestRouter_BacktracksToParam(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/users/new/form", namedHandler("form"))
+	router.Handle("GET", "/users/:id/posts", namedHandler("posts"))
+
+	h, params := router.FindHandler("GET", "/users/new/posts")
+	assert.NotNil(t, h)
+	assert.Equal(t, "posts", h(nil).Message)
+	assert.Equal(t, map[string]string{"id": "new"}, params)
+}
+
+func TestRouter_SharedPrefixes(t *testing.T) {
+	router := NewRouter()
+	paths := []string{"/", "/search", "/support", "/src/:file", "/users/:id/friends/:friend", "/user"}
+	for _, p := range paths {
+		router.Handle("GET", p, namedHandler(p))
+	}
+
+	tests := []struct {
+		path   string
+		want   string
+		params map[string]string
+	}{
+		{"/", "/", map[string]string{}},
+		{"/search", "/search", map[string]string{}},
+		{"/support", "/support", map[string]string{}},
+		{"/src/main.go", "/src/:file", map[string]string{"file": "main.go"}},
+		{"/user", "/user", map[string]string{}},
+		{"/users/1/friends/2", "/users/:id/friends/:friend", map[string]string{"id": "1", "friend": "2"}},
+	}
+	for _, tt := range tests {
+		h, params := router.FindHandler("GET", tt.path)
+		if assert.NotNil(t, h, tt.path) {
+			assert.Equal(t, tt.want, h(nil).Message)
+			assert.Equal(t, tt.params, params)
+		}
+	}
+
+	for _, miss := range []string{"/sea", "/searches", "/src/", "/users/1/friends", "/users"} {
+		h, params := router.FindHandler("GET", miss)
+		assert.Nil(t, h, miss)
+		assert.Nil(t, params)
+	}
+}
+
+func TestRouter_TryHandleConflicts(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/dup", namedHandler("first"))
+	router.Handle("GET", "/users/:id", namedHandler("by-id"))
+	router.Handle("GET", "/files/*path", namedHandler("files"))
+
+	tests := []struct {
+		name, method, path string
+		wantErr            error
+	}{
+		{"duplicate static", "GET", "/dup", ErrRouteConflict},
+		{"duplicate param", "GET", "/users/:id", ErrRouteConflict},
+		{"ambiguous param name", "GET", "/users/:name", ErrRouteConflict},
+		{"ambiguous param name deeper", "GET", "/users/:name/posts", ErrRouteConflict},
+		{"different catch-all name", "GET", "/files/*file", ErrRouteConflict},
+		{"malformed pattern", "GET", "/users/:", ErrInvalidRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, err := router.TryHandle(tt.method, tt.path, namedHandler("second"))
+			assert.Nil(t, rt)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.path)
+		})
+	}
+
+	// Conflicting registrations do not replace or add routes
+	h, _ := router.FindHandler("GET", "/dup")
+	assert.Equal(t, "first", h(nil).Message)
+	h, params := router.FindHandler("GET", "/users/7/posts")
+	assert.Nil(t, h)
+	assert.Nil(t, params)
+	assert.Len(t, router.Routes(), 3)
+
+	// Same path under another method, same param name deeper, or a
+	// differently constrained parameter are all fine
+	for _, ok := range []struct{ method, path string }{
+		{"POST", "/dup"},
+		{"GET", "/users/:id/posts"},
+		{"GET", "/users/:name<alpha>/profile"},
+		{"POST", "/users/:name"},
+	} {
+		_, err := router.TryHandle(ok.method, ok.path, namedHandler("ok"))
+		assert.NoError(t, err, ok.path)
+	}
+}
+
+func TestRouter_HandlePanicsOnConflict(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/users/:id", namedHandler("x"))
+	assert.PanicsWithValue(t,
+		"falcon: route GET /users/:name: route conflict: parameter :name conflicts with existing :id at the same position",
+		func() { router.Handle("GET", "/users/:name", namedHandler("y")) })
+}
+
+func TestRouter_LookupDoesNotAllocate(t *testing.T) {
+	router := NewRouter()
+	for i := 0; i < 200; i++ {
+		router.Handle("GET", fmt.Sprintf("/resource%d/items", i), namedHandler("static"))
+		router.Handle("GET", fmt.Sprintf("/resource%d/items/:id", i), namedHandler("param"))
+	}
+
+	ps := make(Params, 0, 4)
+	staticAllocs := testing.AllocsPerRun(100, func() {
+		ps = ps[:0]
+		router.Lookup("GET", "/resource150/items", &ps)
+	})
+	assert.Zero(t, staticAllocs)
+
+	paramAllocs := testing.AllocsPerRun(100, func() {
+		ps = ps[:0]
+		router.Lookup("GET", "/resource150/items/7", &ps)
+	})
+	assert.Zero(t, paramAllocs)
+	assert.Equal(t, "7", ps.Get("id"))
+}
+
+func TestParams_GetAndMap(t *testing.T) {
+	ps := Params{{Key: "id", Value: "1"}, {Key: "slug", Value: "hello"}}
+	assert.Equal(t, "1", ps.Get("id"))
+	assert.Equal(t, "", ps.Get("missing"))
+	assert.Equal(t, map[string]string{"id": "1", "slug": "hello"}, ps.Map())
+}
+
+func TestRouter_MissingParamNamePanics(t *testing.T) {
+	router := NewRouter()
+	assert.Panics(t, func() {
+		router.Handle("GET", "/users/:", namedHandler("x"))
+	})
+}
+
+func TestRouter_CatchAll(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/static/*filepath", namedHandler("static"))
+	router.Handle("GET", "/static/favicon.ico", namedHandler("favicon"))
+	router.Handle("GET", "/files/*", namedHandler("files"))
+	router.Handle("GET", "/users/:id/*rest", namedHandler("rest"))
+
+	tests := []struct {
+		path   string
+		want   string
+		params map[string]string
+	}{
+		{"/static/css/app.css", "static", map[string]string{"filepath": "css/app.css"}},
+		{"/static/", "static", map[string]string{"filepath": ""}},
+		{"/static/favicon.ico", "favicon", map[string]string{}},
+		{"/static/favicon.ico.map", "static", map[string]string{"filepath": "favicon.ico.map"}},
+		{"/files/a/b/c", "files", map[string]string{"*": "a/b/c"}},
+		{"/users/7/x/y", "rest", map[string]string{"id": "7", "rest": "x/y"}},
+	}
+	for _, tt := range tests {
+		h, params := router.FindHandler("GET", tt.path)
+		if assert.NotNil(t, h, tt.path) {
+			assert.Equal(t, tt.want, h(nil).Message, tt.path)
+			assert.Equal(t, tt.params, params, tt.path)
+		}
+	}
+
+	h, _ := router.FindHandler("GET", "/static")
+	assert.Nil(t, h)
+}
+
+func TestRouter_CatchAllMustBeLast(t *testing.T) {
+	router := NewRouter()
+	assert.Panics(t, func() {
+		router.Handle("GET", "/files/*path/more", namedHandler("x"))
+	})
+
+	router.Handle("GET", "/assets/*path", namedHandler("x"))
+	assert.Panics(t, func() {
+		router.Handle("GET", "/assets/*other", namedHandler("y"))
+	})
+}
+
+func TestRouter_LookupRouteFold(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/users/:id/Posts", namedHandler("posts"))
+	router.Handle("GET", "/static/*path", namedHandler("static"))
+
+	var ps Params
+	assert.Nil(t, router.LookupRoute("GET", "/USERS/Ab/posts", &ps))
+
+	rt := router.LookupRouteFold("GET", "/USERS/Ab/posts", &ps)
+	if assert.NotNil(t, rt) {
+		assert.Equal(t, "/users/:id/Posts", rt.Path)
+		assert.Equal(t, "/users/Ab/Posts", rt.Expand(ps))
+	}
+
+	ps = ps[:0]
+	rt = router.LookupRouteFold("GET", "/Static/CSS/App.css", &ps)
+	if assert.NotNil(t, rt) {
+		assert.Equal(t, "/static/CSS/App.css", rt.Expand(ps))
+	}
+}
+
+func TestRouter_LookupRouteEscaped(t *testing.T) {
+	router := NewRouter()
+	router.Handle("GET", "/users/:id", namedHandler("user"))
+	router.Handle("DELETE", "/users/:id", namedHandler("delete"))
+	router.Handle("GET", "/files/:dir/:name<alpha>", namedHandler("file"))
+
+	var ps Params
+	rt := router.LookupRouteEscaped("GET", "/%75sers/1", &ps, false)
+	if assert.NotNil(t, rt) {
+		assert.Equal(t, "/users/:id", rt.Path)
+		assert.Equal(t, "1", ps.Get("id"))
+	}
+
+	ps = ps[:0]
+	rt = router.LookupRouteEscaped("GET", "/files/a%2Fb/%41bc", &ps, false)
+	if assert.NotNil(t, rt) {
+		assert.Equal(t, "a%2Fb", ps.Get("dir"), "values are captured escaped")
+		assert.Equal(t, "%41bc", ps.Get("name"))
+	}
+
+	ps = ps[:0]
+	assert.Nil(t, router.LookupRouteEscaped("GET", "/files/a/%31bc", &ps, false), "constraints see the unescaped value")
+	assert.Nil(t, router.LookupRouteEscaped("GET", "/users%2F1", &ps, false), "an encoded slash is not a separator")
+	assert.Nil(t, router.LookupRouteEscaped("GET", "/%55sers/1", &ps, false))
+	assert.NotNil(t, router.LookupRouteEscaped("GET", "/%55sers/1", &ps, true))
+
+	assert.Equal(t, []string{"DELETE", "GET"}, router.AllowedMethodsEscaped("/%75sers/1", false))
+	assert.Empty(t, router.AllowedMethodsEscaped("/%75sers%2F1", false))
+}
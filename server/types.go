@@ -6,20 +6,55 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-// route represents a single registered route in the router.
+// Route represents a single registered route in the router.
 // It contains the HTTP method, the route path pattern, and the handler function.
-type route struct {
-	Method  string      // HTTP method (GET, POST, PUT, etc.)
-	Path    string      // Route pattern, e.g. "/users/:id"
-	Handler HandlerFunc // Function to handle requests matching this route
+// Router.Handle returns it so callers can attach a name for URL generation.
+type Route struct {
+	Method      string            // HTTP method (GET, POST, PUT, etc.)
+	Path        string            // Route pattern, e.g. "/users/:id"
+	Handler     HandlerFunc       // Function to handle requests matching this route
+	Constraints map[string]string // Parameter name -> constraint, e.g. "id" -> "int"
+	HandlerName string            // Name of the handler function, for introspection
+
+	name     string    // Optional name used for reverse URL generation
+	segments []segment // Parsed pattern, reused when building URLs
+	router   *Router   // Router the route is registered in
+}
+
+// RouteInfo describes a registered route for introspection. Host is the
+// host pattern of routes registered for a specific host, if any.
+// Constraints lists the constraint attached to each constrained parameter,
+// which explains why a request that looks like it should match got a 404.
+type RouteInfo struct {
+	Method      string            `json:"method"`
+	Host        string            `json:"host,omitempty"`
+	Path        string            `json:"path"`
+	Name        string            `json:"name,omitempty"`
+	Handler     string            `json:"handler"`
+	Middlewares int               `json:"middlewares"`
+	Constraints map[string]string `json:"constraints,omitempty"`
 }
 
-// Router is a minimal HTTP router that supports method-based routing
-// and simple path parameters (e.g., /users/:id).
+// Router is an HTTP router that keeps one compressed prefix tree per HTTP
+// method and supports path parameters (e.g., /users/:id) and trailing
+// catch-all parameters (e.g., /static/*filepath).
+// Static segments always take priority over parameters, regardless of
+// the order in which routes were registered.
 type Router struct {
-	routes []route // List of all registered routes
+	trees  map[string]*node  // Root of the routing tree for each HTTP method
+	routes []*Route          // List of all registered routes, in registration order
+	names  map[string]*Route // Named routes, used for reverse URL generation
 }
 
+// Param is a single path parameter captured while routing a request.
+type Param struct {
+	Key   string // Parameter name, e.g. "id" for "/users/:id"
+	Value string // Value taken from the request path
+}
+
+// Params is an ordered list of path parameters captured for a request.
+type Params []Param
+
 // Response is the unified return type for all handlers in Falcon.
 // It is automatically serialized to JSON and written to the client.
 // Fields:
@@ -0,0 +1,220 @@
+package falcon
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ascendingheavens/falcon/server"
+)
+
+// StaticConfig defines how files are served from a file system.
+type StaticConfig struct {
+	// Index is the file served for directory requests. Defaults to
+	// "index.html". Directories without one answer 404; they are never
+	// listed.
+	Index string
+
+	// SPA serves the root index file instead of 404 for paths that do not
+	// exist, so client-side routes of a single-page application load.
+	SPA bool
+
+	// Precompressed serves "name.br" or "name.gz" instead of "name" when
+	// the file exists next to the original and the client accepts that
+	// encoding.
+	Precompressed bool
+
+	// MaxAge sets "Cache-Control: public, max-age=..." when positive.
+	MaxAge time.Duration
+}
+
+// Static serves the files in the directory root under prefix.
+// Files get their MIME type from the extension, directories their
+// index.html, and Range, If-Modified-Since and If-None-Match (ETag)
+// requests are answered as usual. Paths cannot escape root, not even
+// through symbolic links. Static panics if root cannot be opened.
+// Example:
+//
+//	app.Static("/assets", "./public")
+func (s *Server) Static(prefix, root string) []*Route {
+	return s.StaticFS(prefix, openStaticRoot(root))
+}
+
+// StaticFS serves the files in fsys under prefix like Static, e.g. from
+// an embed.FS.
+// Example:
+//
+//	//go:embed public
+//	var public embed.FS
+//
+//	sub, _ := fs.Sub(public, "public")
+//	app.StaticFS("/assets", sub)
+func (s *Server) StaticFS(prefix string, fsys fs.FS) []*Route {
+	return s.StaticWithConfig(prefix, fsys, StaticConfig{})
+}
+
+// StaticWithConfig serves the files in fsys under prefix using cfg.
+// Example:
+//
+//	app.StaticWithConfig("/", os.DirFS("./dist"), falcon.StaticConfig{SPA: true, Precompressed: true})
+func (s *Server) StaticWithConfig(prefix string, fsys fs.FS, cfg StaticConfig) []*Route {
+	return static(s, prefix, fsys, cfg)
+}
+
+// Static serves the files in the directory root under the group prefix
+// plus prefix, running the group middleware.
+func (g *Group) Static(prefix, root string) []*Route {
+	return g.StaticFS(prefix, openStaticRoot(root))
+}
+
+// StaticFS serves the files in fsys under the group prefix plus prefix.
+func (g *Group) StaticFS(prefix string, fsys fs.FS) []*Route {
+	return g.StaticWithConfig(prefix, fsys, StaticConfig{})
+}
+
+// StaticWithConfig serves the files in fsys under the group using cfg.
+func (g *Group) StaticWithConfig(prefix string, fsys fs.FS, cfg StaticConfig) []*Route {
+	return static(g, prefix, fsys, cfg)
+}
+
+// openStaticRoot opens dir so that lookups cannot leave it.
+func openStaticRoot(dir string) fs.FS {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		panic("falcon: static root: " + err.Error())
+	}
+	return root.FS()
+}
+
+// static registers GET routes for prefix and everything below it on
+// target. HEAD is answered by the GET routes automatically.
+func static(target Handler, prefix string, fsys fs.FS, cfg StaticConfig) []*Route {
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+	fh := &fileHandler{fsys: fsys, cfg: cfg}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	routes := []*Route{target.GET(prefix+"/*filepath", fh.serve)}
+	if prefix != "" {
+		routes = append(routes, target.GET(prefix, fh.serve))
+	}
+	return routes
+}
+
+// fileHandler serves files from an fs.FS.
+type fileHandler struct {
+	fsys  fs.FS
+	cfg   StaticConfig
+	etags sync.Map // file name -> ETag for files without a modification time
+}
+
+// precompressed lists the encodings tried with StaticConfig.Precompressed,
+// in order of preference, with the file suffix holding that encoding.
+var precompressed = []struct{ encoding, suffix string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// serve answers a request for the file named by the catch-all parameter.
+func (h *fileHandler) serve(c *server.Context) *server.Response {
+	name := path.Clean("/" + c.Param("filepath"))[1:]
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return c.FileError(fs.ErrNotExist)
+	}
+
+	name, info, err := h.resolve(name)
+	if errors.Is(err, fs.ErrNotExist) && h.cfg.SPA {
+		name, info, err = h.resolve(".")
+	}
+	if err != nil {
+		return c.FileError(err)
+	}
+	return h.serveFile(c, name, info)
+}
+
+// resolve returns the file to serve for name, which is the index file for
+// directories.
+func (h *fileHandler) resolve(name string) (string, fs.FileInfo, error) {
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.IsDir() {
+		name = path.Join(name, h.cfg.Index)
+		if info, err = fs.Stat(h.fsys, name); err != nil {
+			return "", nil, err
+		}
+		if info.IsDir() {
+			return "", nil, fs.ErrNotExist
+		}
+	}
+	return name, info, nil
+}
+
+// serveFile writes the file, or a precompressed variant of it, through
+// Context.ServeFile, which handles Range and conditional requests.
+func (h *fileHandler) serveFile(c *server.Context, name string, info fs.FileInfo) *server.Response {
+	header := http.Header{}
+	if h.cfg.MaxAge > 0 {
+		header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.cfg.MaxAge.Seconds())))
+	}
+
+	servedName, servedInfo := name, info
+	if h.cfg.Precompressed {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		accept := c.Request.Header.Get("Accept-Encoding")
+		for _, p := range precompressed {
+			if !acceptsEncoding(accept, p.encoding) {
+				continue
+			}
+			if ci, err := fs.Stat(h.fsys, name+p.suffix); err == nil && !ci.IsDir() {
+				header.Set("Content-Encoding", p.encoding)
+				servedName, servedInfo = name+p.suffix, ci
+				break
+			}
+		}
+	}
+
+	// Files without a modification time (embed.FS) are hashed for their
+	// ETag once, not on every request
+	hashed := servedInfo.ModTime().IsZero()
+	if tag, ok := h.etags.Load(servedName); ok && hashed {
+		header.Set("ETag", tag.(string))
+	}
+
+	f, err := h.fsys.Open(servedName)
+	if err != nil {
+		return c.FileError(err)
+	}
+	defer f.Close()
+
+	resp := c.ServeFile(f, name, header)
+	if tag := c.Writer.Header().Get("ETag"); hashed && resp.Success && tag != "" {
+		h.etags.Store(servedName, tag)
+	}
+	return resp
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header value allows
+// encoding, ignoring entries with q=0.
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package falcon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveStatic(s *Server, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Static(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.css"), []byte("body{color:red}"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<h1>home</h1>"), 0o644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "empty"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(t.TempDir(), "secret.txt"), []byte("secret"), 0o644))
+
+	s := New()
+	s.Static("/assets", dir)
+
+	t.Run("mime type from extension", func(t *testing.T) {
+		rec := serveStatic(s, http.MethodGet, "/assets/app.css", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "body{color:red}", rec.Body.String())
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+		assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+	})
+
+	t.Run("index", func(t *testing.T) {
+		for _, target := range []string{"/assets", "/assets/"} {
+			rec := serveStatic(s, http.MethodGet, target, nil)
+			assert.Equal(t, http.StatusOK, rec.Code, target)
+			assert.Equal(t, "<h1>home</h1>", rec.Body.String(), target)
+		}
+		rec := serveStatic(s, http.MethodGet, "/assets/empty/", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		rec := serveStatic(s, http.MethodGet, "/assets/app.css", map[string]string{"Range": "bytes=0-3"})
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "body", rec.Body.String())
+	})
+
+	t.Run("conditional", func(t *testing.T) {
+		first := serveStatic(s, http.MethodGet, "/assets/app.css", nil)
+
+		rec := serveStatic(s, http.MethodGet, "/assets/app.css", map[string]string{"If-None-Match": first.Header().Get("ETag")})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+
+		rec = serveStatic(s, http.MethodGet, "/assets/app.css", map[string]string{"If-Modified-Since": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("head", func(t *testing.T) {
+		rec := serveStatic(s, http.MethodHead, "/assets/app.css", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "15", rec.Header().Get("Content-Length"))
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("traversal", func(t *testing.T) {
+		for _, target := range []string{"/assets/../secret.txt", "/assets/%2e%2e/secret.txt", "/assets/..%2fsecret.txt", "/assets/missing.js"} {
+			rec := serveStatic(s, http.MethodGet, target, nil)
+			assert.Equal(t, http.StatusNotFound, rec.Code, target)
+			assert.NotContains(t, rec.Body.String(), "secret", target)
+		}
+	})
+
+	t.Run("other methods", func(t *testing.T) {
+		rec := serveStatic(s, http.MethodPost, "/assets/app.css", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func TestServer_StaticRejectsSymlinkEscape(t *testing.T) {
+	outside := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644))
+	dir := t.TempDir()
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "link.txt")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	s := New()
+	s.Static("/", dir)
+	rec := serveStatic(s, http.MethodGet, "/link.txt", nil)
+	assert.NotEqual(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+}
+
+func TestServer_StaticFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":        {Data: []byte("<div id=app></div>")},
+		"js/app.js":         {Data: []byte("console.log('plain')")},
+		"js/app.js.br":      {Data: []byte("brotli bytes")},
+		"js/app.js.gz":      {Data: []byte("gzip bytes")},
+		"css/site.css":      {Data: []byte("a{}")},
+		"css/site.css.gz":   {Data: []byte("gzip css")},
+		"docs/index.html":   {Data: []byte("docs")},
+		"docs/guide/x.html": {Data: []byte("x")},
+	}
+
+	s := New()
+	s.StaticWithConfig("/", fsys, StaticConfig{SPA: true, Precompressed: true, MaxAge: time.Hour})
+
+	t.Run("precompressed", func(t *testing.T) {
+		rec := serveStatic(s, http.MethodGet, "/js/app.js", map[string]string{"Accept-Encoding": "gzip, br"})
+		assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "brotli bytes", rec.Body.String())
+		assert.Contains(t, rec.Header().Get("Content-Type"), "javascript")
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+		rec = serveStatic(s, http.MethodGet, "/js/app.js", map[string]string{"Accept-Encoding": "gzip, br;q=0"})
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+		rec = serveStatic(s, http.MethodGet, "/css/site.css", map[string]string{"Accept-Encoding": "br"})
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "a{}", rec.Body.String())
+	})
+
+	t.Run("etag from content", func(t *testing.T) {
+		first := serveStatic(s, http.MethodGet, "/css/site.css", nil)
+		tag := first.Header().Get("ETag")
+		assert.NotEmpty(t, tag)
+		assert.Equal(t, "public, max-age=3600", first.Header().Get("Cache-Control"))
+
+		rec := serveStatic(s, http.MethodGet, "/css/site.css", map[string]string{"If-None-Match": tag})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("spa fallback", func(t *testing.T) {
+		rec := serveStatic(s, http.MethodGet, "/users/42/settings", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<div id=app></div>", rec.Body.String())
+
+		rec = serveStatic(s, http.MethodGet, "/docs/", nil)
+		assert.Equal(t, "docs", rec.Body.String())
+	})
+}
+
+func TestGroup_StaticRunsGroupMiddleware(t *testing.T) {
+	s := New()
+	g := s.Group("/public")
+	g.Use(headerMiddleware("X-Group"))
+	g.StaticFS("/files", fstest.MapFS{"a.txt": {Data: []byte("a")}})
+
+	rec := serveStatic(s, http.MethodGet, "/public/files/a.txt", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "a", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("X-Group"))
+}
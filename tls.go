@@ -1,20 +1,16 @@
 package falcon
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"log"
 	"net/http"
-
-	"golang.org/x/crypto/acme/autocert"
+	"os"
+	"time"
 )
 
-// listenAndServeTLS is a package-level variable that wraps http.ListenAndServeTLS
-// for dependency injection during testing. This allows tests to mock the TLS
-// server startup without actually starting a real server.
-var listenAndServeTLS = func(addr, certFile, keyFile string, h http.Handler) error {
-	return http.ListenAndServeTLS(addr, certFile, keyFile, h)
-}
-
 // logFatal is a package-level variable that wraps log.Fatal for dependency
 // injection during testing. This allows tests to capture fatal errors without
 // actually terminating the test process.
@@ -31,8 +27,8 @@ var logFatal = func(v ...interface{}) {
 //   - keyFile: Path to the TLS private key file
 //
 // This method will call log.Fatal if the server fails to start, terminating
-// the program. Use this for production deployments where server startup
-// failure should halt the application.
+// the program. It returns after a graceful Shutdown. Use RunTLS to get
+// the error instead.
 //
 // Example:
 //
@@ -40,7 +36,7 @@ var logFatal = func(v ...interface{}) {
 //	server.StartTLS(":443", "/path/to/cert.pem", "/path/to/key.pem")
 func (s *Server) StartTLS(addr, certFile, keyFile string) {
 	log.Printf("Starting server with TLS on %s", addr)
-	if err := listenAndServeTLS(addr, certFile, keyFile, s); err != nil {
+	if err := s.RunTLS(context.Background(), addr, certFile, keyFile); err != nil {
 		logFatal(err)
 	}
 }
@@ -50,9 +46,12 @@ func (s *Server) StartTLS(addr, certFile, keyFile string) {
 // the Server to start itself when used with StartAutoTLSWithStarter.
 //
 // This method assumes the server is already configured with TLS settings and
-// will call log.Fatal if the server fails to start.
+// will call log.Fatal if the server fails to start. Like StartTLS it
+// returns after a graceful Shutdown.
 func (s *Server) startTLSServer(server *http.Server) {
-	logFatal(server.ListenAndServeTLS("", ""))
+	if err := s.runServer(context.Background(), server, true, "", ""); err != nil {
+		logFatal(err)
+	}
 }
 
 // StartAutoTLS starts the server with automatic TLS certificate management using Let's Encrypt.
@@ -69,9 +68,13 @@ func (s *Server) startTLSServer(server *http.Server) {
 //   - TLS configuration with automatic certificate retrieval
 //
 // Requirements:
-//   - The server must be accessible from the internet on port 443
+//   - The server must be accessible from the internet on port 443, where
+//     the ACME TLS-ALPN-01 challenge is answered
 //   - The domain must point to the server's IP address
-//   - Port 80 should also be available for ACME challenges (handled automatically by autocert)
+//
+// No server is started on port 80; use StartAutoTLSWithConfig with
+// HTTPAddr for the HTTP-01 challenge and HTTP to HTTPS redirects, more
+// hosts, another cache or ACME directory.
 //
 // This method will call log.Fatal if the server fails to start, terminating
 // the program. Use this for production deployments where server startup
@@ -110,18 +113,110 @@ func (s *Server) StartAutoTLS(domain string) {
 //	mockStarter := &MockTLSStarter{...}
 //	server.StartAutoTLSWithStarter("example.com", mockStarter)
 func (s *Server) StartAutoTLSWithStarter(domain string, starter TLSStarter) {
-	manager := &autocert.Manager{
-		Cache:      autocert.DirCache("certs"),
-		Prompt:     autocert.AcceptTOS,
-		HostPolicy: autocert.HostWhitelist(domain),
+	log.Printf("Starting server with AutoTLS on %s", domain)
+	srv, _, err := s.autoTLSServer(AutoTLSConfig{Hosts: []string{domain}})
+	if err != nil {
+		logFatal(err)
+		return
+	}
+	starter.startTLSServer(srv)
+}
+
+// TLSConfig defines how StartTLSWithConfig and RunTLSWithConfig serve
+// HTTPS.
+type TLSConfig struct {
+	// CertFile and KeyFile hold the PEM encoded certificate chain and
+	// private key.
+	CertFile string
+	KeyFile  string
+
+	// ReloadInterval re-reads CertFile and KeyFile this often and swaps
+	// in the new pair when they change, keeping the current one while
+	// the files hold an invalid pair. Failures are logged to
+	// Config.ErrorLog. Zero loads the files once.
+	ReloadInterval time.Duration
+
+	// ClientCAFile holds the PEM encoded CA certificates client
+	// certificates are verified against, enabling mutual TLS. Verified
+	// client certificates are available through c.ClientCert().
+	ClientCAFile string
+
+	// ClientAuth is the client certificate policy. It defaults to
+	// tls.RequireAndVerifyClientCert when ClientCAFile is set; use
+	// tls.VerifyClientCertIfGiven to also accept clients without one.
+	// Policies that verify certificates require ClientCAFile, so clients
+	// are never checked against the system roots.
+	ClientAuth tls.ClientAuthType
+}
+
+// StartTLSWithConfig starts the server with TLS using cfg like StartTLS.
+// Example:
+//
+//	// pick up certificates rotated by cert-manager without a restart
+//	server.StartTLSWithConfig(":443", falcon.TLSConfig{
+//		CertFile:       "/etc/tls/tls.crt",
+//		KeyFile:        "/etc/tls/tls.key",
+//		ReloadInterval: time.Minute,
+//	})
+func (s *Server) StartTLSWithConfig(addr string, cfg TLSConfig) {
+	log.Printf("Starting server with TLS on %s", addr)
+	if err := s.RunTLSWithConfig(context.Background(), addr, cfg); err != nil {
+		logFatal(err)
 	}
+}
 
-	server := &http.Server{
-		Addr:      ":443",
-		Handler:   s,
-		TLSConfig: &tls.Config{GetCertificate: manager.GetCertificate},
+// RunTLSWithConfig serves HTTPS on addr using cfg like RunTLS. It fails
+// without listening if the certificate or client CAs cannot be loaded, or
+// if cfg.ClientAuth verifies client certificates without cfg.ClientCAFile.
+// Example:
+//
+//	// mutual TLS for service-to-service calls
+//	err := app.RunTLSWithConfig(ctx, ":8443", falcon.TLSConfig{
+//		CertFile:     "/etc/tls/tls.crt",
+//		KeyFile:      "/etc/tls/tls.key",
+//		ClientCAFile: "/etc/tls/ca.crt",
+//	})
+func (s *Server) RunTLSWithConfig(ctx context.Context, addr string, cfg TLSConfig) error {
+	verify := cfg.ClientAuth == tls.VerifyClientCertIfGiven || cfg.ClientAuth == tls.RequireAndVerifyClientCert
+	if verify && cfg.ClientCAFile == "" {
+		return fmt.Errorf("falcon: TLSConfig.ClientAuth %s needs a ClientCAFile", cfg.ClientAuth)
 	}
 
-	log.Printf("Starting server with AutoTLS on %s", domain)
-	starter.startTLSServer(server)
+	reloader, err := NewCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: reloader.GetCertificate}
+	if cfg.ClientCAFile != "" {
+		if tlsCfg.ClientCAs, err = loadCertPool(cfg.ClientCAFile); err != nil {
+			return err
+		}
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if cfg.ClientAuth != tls.NoClientCert {
+		tlsCfg.ClientAuth = cfg.ClientAuth
+	}
+
+	if cfg.ReloadInterval > 0 {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go reloader.Watch(watchCtx, cfg.ReloadInterval, s.Config().ErrorLog)
+	}
+
+	srv := s.newHTTPServer(addr)
+	srv.TLSConfig = tlsCfg
+	return s.runServer(ctx, srv, true, "", "")
+}
+
+// loadCertPool returns a pool with the PEM encoded certificates in file.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("falcon: no certificates found in %s", file)
+	}
+	return pool, nil
 }
@@ -1,7 +1,10 @@
 package falcon
 
 import (
+	"io/fs"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ascendingheavens/falcon/middleware"
 	"github.com/ascendingheavens/falcon/server"
@@ -20,16 +23,56 @@ type Server struct {
 	middlewares []middleware.Middleware
 
 	// conditionalMiddleware is a slice of middleware that only run when the
-	// incoming request path matches the provided pattern.
-	// For example, you might apply authentication middleware only for
-	// `/api/*` routes.
-	conditionalMiddleware []middleware.ConditionalMiddleware
+	// incoming request matches the provided conditions, compiled when they
+	// are registered. For example, you might apply authentication
+	// middleware only for `/api/*` routes.
+	conditionalMiddleware []*condition
+
+	// groups lists every group created with Group, used to find the
+	// NotFound and MethodNotAllowed overrides for unmatched paths.
+	groups []*Group
+
+	// hosts holds the routers created with Host, static hosts first.
+	hosts []*hostRouter
+
+	// routing controls how paths that only match after normalization
+	// (trailing slash, cleaning, case) are handled. See SetRouting.
+	routing RoutingConfig
+
+	// routes lists every route registered through the Server or a Group,
+	// with the group it belongs to, in registration order.
+	routes []routeEntry
+
+	// chains caches the middleware-wrapped handlers requests are
+	// dispatched to. It is built on the first request (or by Start) and
+	// reset whenever a route or middleware is registered.
+	chains  atomic.Pointer[chains]
+	buildMu sync.Mutex
+
+	// life tracks the running http.Servers for graceful shutdown.
+	life lifecycle
+
+	// hooks holds the lifecycle and request hooks, see OnStart.
+	hooks hooks
+
+	// config holds the http.Server settings, see Configure. Guarded by
+	// life.mu.
+	config Config
+
+	// notFound handles requests that match no route.
+	// When nil, a JSON 404 Response is returned.
+	notFound server.HandlerFunc
+
+	// methodNotAllowed handles requests whose path is registered under other
+	// HTTP methods. When nil, a JSON 405 Response is returned.
+	methodNotAllowed server.HandlerFunc
 }
 
 // Group represents a collection of routes that share a common path prefix
 // and middleware stack. Useful for organizing related endpoints like `/api/v1/*`.
 type Group struct {
-	// Prefix is the base path for this group (e.g., "/api/v1").
+	// Prefix is the base path for this group (e.g., "/api/v1"), including
+	// the prefixes of any parent groups.
 	Prefix string
 
 	// Server is a reference back to the parent server, allowing
@@ -40,6 +83,19 @@ type Group struct {
 	// every route registered within this group, in addition to any
 	// global or conditional middleware from the Server.
 	Middlewares []middleware.Middleware
+
+	// parent is the enclosing group for groups created with Group.Group.
+	// Its middleware (and its ancestors') wraps every route of this group.
+	parent *Group
+
+	// host restricts the group to requests for one host pattern. It is set
+	// for groups created with Host and inherited by their child groups.
+	host *hostRouter
+
+	// notFound and methodNotAllowed override the server fallbacks for
+	// unmatched paths under Prefix.
+	notFound         HandlerFunc
+	methodNotAllowed HandlerFunc
 }
 
 // Context is an alias to server.Context, which wraps the request and response
@@ -56,13 +112,22 @@ type Response = server.Response
 type Middleware = middleware.Middleware
 
 // ConditionalMiddleware is an alias to middleware.ConditionalMiddleware,
-// which pairs a pattern (e.g., "/api/*") with a Middleware function.
+// which pairs path, method, host or custom filters with a Middleware
+// function. Register it with UseWhen.
 type ConditionalMiddleware = middleware.ConditionalMiddleware
 
+// Route is an alias to server.Route, a registered route returned by the
+// registration methods. Call Name on it to enable reverse URL generation.
+type Route = server.Route
+
 // HandlerFunc is an alias to server.HandlerFunc, the function signature
 // that route handlers must implement. It takes a *Context and returns a *Response.
 type HandlerFunc = server.HandlerFunc
 
+// ClientCert is an alias to server.ClientCert, the verified client
+// certificate of a mutual TLS request, available through c.ClientCert().
+type ClientCert = server.ClientCert
+
 // TLSStarter defines an interface for starting a TLS server.
 // Implementations should provide the startTLSServer method to handle
 // the server startup logic for HTTPS.
@@ -79,6 +144,22 @@ type TemplateRenderer = server.TemplateRenderer
 // using standard HTTP methods. This allows groups and servers to be
 // used interchangeably when registering routes.
 type Handler interface {
+	// Group creates a route group whose prefix is appended to this
+	// Handler's prefix. A group created from another group inherits its
+	// middleware.
+	//
+	// Example:
+	//   v1 := h.Group("/v1")
+	Group(prefix string) *Group
+
+	// Route creates a route group like Group and passes it to fn.
+	//
+	// Example:
+	//   h.Route("/users", func(g *Group) {
+	//       g.GET("/:id", getUserHandler)
+	//   })
+	Route(prefix string, fn func(g *Group)) *Group
+
 	// Use registers a middleware that will be applied to all routes
 	// registered through this Handler. Middleware functions are executed
 	// in the order they are added, with the last registered executed first.
@@ -99,7 +180,20 @@ type Handler interface {
 	//
 	// This is the lowest-level route registration function and is used
 	// internally by convenience methods like GET, POST, etc.
-	Handle(method, path string, handler HandlerFunc)
+	// The returned Route can be named for reverse URL generation.
+	// It panics if the route is malformed or conflicts with an existing one.
+	Handle(method, path string, handler HandlerFunc) *Route
+
+	// TryHandle registers a route like Handle but returns an error instead
+	// of panicking when the path is malformed or conflicts with an existing
+	// route, e.g. the same method and path twice or "/users/:id" next to
+	// "/users/:name".
+	//
+	// Example:
+	//   if _, err := h.TryHandle(http.MethodGet, "/users/:id", getUserHandler); err != nil {
+	//       log.Fatal(err)
+	//   }
+	TryHandle(method, path string, handler HandlerFunc) (*Route, error)
 
 	// GET registers a route that matches HTTP GET requests at the given path.
 	// The handler is called when an incoming request's method is GET and
@@ -107,7 +201,7 @@ type Handler interface {
 	//
 	// Example:
 	//   h.GET("/users", getUsersHandler)
-	GET(path string, handler HandlerFunc)
+	GET(path string, handler HandlerFunc) *Route
 
 	// POST registers a route that matches HTTP POST requests at the given path.
 	// The handler is called when an incoming request's method is POST and
@@ -115,26 +209,88 @@ type Handler interface {
 	//
 	// Example:
 	//   h.POST("/users", createUserHandler)
-	POST(path string, handler HandlerFunc)
+	POST(path string, handler HandlerFunc) *Route
 
 	// PUT registers a route that matches HTTP PUT requests at the given path.
 	// Typically used for replacing existing resources.
 	//
 	// Example:
 	//   h.PUT("/users/:id", updateUserHandler)
-	PUT(path string, handler HandlerFunc)
+	PUT(path string, handler HandlerFunc) *Route
 
 	// PATCH registers a route that matches HTTP PATCH requests at the given path.
 	// Typically used for partially updating existing resources.
 	//
 	// Example:
 	//   h.PATCH("/users/:id", partiallyUpdateUserHandler)
-	PATCH(path string, handler HandlerFunc)
+	PATCH(path string, handler HandlerFunc) *Route
 
 	// DELETE registers a route that matches HTTP DELETE requests at the given path.
 	// Typically used for deleting resources.
 	//
 	// Example:
 	//   h.DELETE("/users/:id", deleteUserHandler)
-	DELETE(path string, handler HandlerFunc)
+	DELETE(path string, handler HandlerFunc) *Route
+
+	// HEAD registers a route that matches HTTP HEAD requests at the given path.
+	// GET routes already answer HEAD automatically, so this is only needed
+	// when HEAD must behave differently.
+	//
+	// Example:
+	//   h.HEAD("/files/:id", fileInfoHandler)
+	HEAD(path string, handler HandlerFunc) *Route
+
+	// OPTIONS registers a route that matches HTTP OPTIONS requests at the given path.
+	// Paths without one answer OPTIONS automatically with an Allow header.
+	//
+	// Example:
+	//   h.OPTIONS("/users", describeUsersHandler)
+	OPTIONS(path string, handler HandlerFunc) *Route
+
+	// Any registers a route that matches every standard HTTP method at the given path.
+	//
+	// Example:
+	//   h.Any("/proxy/*path", proxyHandler)
+	Any(path string, handler HandlerFunc) []*Route
+
+	// Match registers a route that matches each of the given HTTP methods at the path.
+	//
+	// Example:
+	//   h.Match([]string{http.MethodGet, http.MethodPost}, "/login", loginHandler)
+	Match(methods []string, path string, handler HandlerFunc) []*Route
+
+	// Mount serves every request under prefix, for any method, with a
+	// standard http.Handler (or another Falcon Server). The prefix is
+	// stripped from the request path and the middleware chain still runs.
+	//
+	// Example:
+	//   h.Mount("/admin", adminApp)
+	Mount(prefix string, h http.Handler) []*Route
+
+	// MountWithConfig mounts a standard http.Handler like Mount, using cfg,
+	// e.g. to keep the prefix for handlers that route on the full path.
+	//
+	// Example:
+	//   h.MountWithConfig("/debug/pprof", http.DefaultServeMux, MountConfig{KeepPrefix: true})
+	MountWithConfig(prefix string, h http.Handler, cfg MountConfig) []*Route
+
+	// Static serves the files in a directory under prefix, with MIME
+	// types, index.html, Range and conditional requests handled.
+	//
+	// Example:
+	//   h.Static("/assets", "./public")
+	Static(prefix, root string) []*Route
+
+	// StaticFS serves the files in an fs.FS, such as an embed.FS, under prefix.
+	//
+	// Example:
+	//   h.StaticFS("/assets", assets)
+	StaticFS(prefix string, fsys fs.FS) []*Route
+
+	// StaticWithConfig serves the files in an fs.FS under prefix using cfg,
+	// e.g. with a single-page application fallback or precompressed files.
+	//
+	// Example:
+	//   h.StaticWithConfig("/", dist, StaticConfig{SPA: true})
+	StaticWithConfig(prefix string, fsys fs.FS, cfg StaticConfig) []*Route
 }
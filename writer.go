@@ -0,0 +1,49 @@
+package falcon
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// headResponseWriter lets a GET handler answer a HEAD request. The body is
+// discarded, and the status is held back until the handler finishes so
+// Content-Length can be filled in from the bytes it tried to write.
+type headResponseWriter struct {
+	http.ResponseWriter
+	code    int
+	written int
+}
+
+// WriteHeader records the status code; it is sent by flush.
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// Write counts and discards the body.
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.written += len(b)
+	return len(b), nil
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flush sends the recorded status along with a Content-Length header,
+// unless the handler set one itself or the status forbids a body.
+func (w *headResponseWriter) flush() {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	bodyAllowed := w.code >= 200 && w.code != http.StatusNoContent && w.code != http.StatusNotModified
+	if bodyAllowed && w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.written))
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}